@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"github.com/dustin/go-humanize/english"
+	"golang.org/x/exp/slices"
+	"os"
+	"strings"
+)
+
+type displayMode int
+
+const (
+	StandardDisplay displayMode = iota
+	// Marks cells using glyphs as well as colours, e.g. for colour-blind players or terminals without colour support
+	MarkersDisplay
+	// Describes the board in plain text instead of drawing it, for use with a screen reader
+	ScreenReaderDisplay
+)
+
+var allDisplayModes = []displayMode{StandardDisplay, MarkersDisplay, ScreenReaderDisplay}
+
+func (d displayMode) String() string {
+	return [...]string{"Standard", "Markers", "Screen reader"}[d]
+}
+
+func toggleDisplayMode(d displayMode) displayMode {
+	return allDisplayModes[(slices.Index(allDisplayModes, d)+1)%len(allDisplayModes)]
+}
+
+// Returns whether colour has been disabled using the NO_COLOR environment variable (see https://no-color.org)
+func isColorDisabled() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// Returns the number of spaces between the grid's border and its cells
+func getGridPadding(m model) int {
+	if m.displayMode == MarkersDisplay {
+		return 1
+	}
+	return 0
+}
+
+// Returns the grid currently being shown, which differs from the game's grid when analysing or replaying a game
+func getDisplayedGrid(m model) grid {
+	switch {
+	case m.view == AnalysisView && len(m.analysis) > 0:
+		return m.analysis[m.analysisIndex].gridAfter
+	case m.view == ReplayView:
+		return m.replay.states[m.replay.index].grid
+	case m.view == PuzzleView:
+		return m.puzzles.getGrid()
+	case m.view == TutorialView:
+		return m.tutorial.getGrid()
+	case m.view == EditorView:
+		return m.editor.state.grid
+	default:
+		return m.grid
+	}
+}
+
+func formatPoints(points []vector2d) string {
+	if len(points) == 0 {
+		return "none"
+	}
+
+	pointStrings := make([]string, 0, len(points))
+	for _, p := range points {
+		pointStrings = append(pointStrings, p.String())
+	}
+	return strings.Join(pointStrings, ", ")
+}
+
+// Describes the board as a list of lines, to be read out in order by a screen reader
+func createBoardDescription(m model) string {
+	g := getDisplayedGrid(m)
+
+	disks := map[player][]vector2d{}
+	for i, row := range g {
+		for j, cell := range row {
+			if cell != Blank {
+				disks[cell] = append(disks[cell], vector2d{j, i})
+			}
+		}
+	}
+
+	lines := make([]string, 0, 7)
+	for _, p := range []player{DarkPlayer, LightPlayer} {
+		lines = append(lines, fmt.Sprintf("%s (%s): %s on %s", p, p.toSymbol(),
+			english.Plural(len(disks[p]), "disk", ""), formatPoints(disks[p])))
+	}
+	if len(disks[Blocked]) > 0 {
+		lines = append(lines, fmt.Sprintf("Blocked cells: %s", formatPoints(disks[Blocked])))
+	}
+
+	moves := m.record.moves
+	disksFlipped := m.disksFlipped
+	switch m.view {
+	case ReplayView:
+		moves = moves[:m.replay.index]
+		disksFlipped = m.replay.getDisksFlipped(m.record)
+	case AnalysisView, EditorView:
+		moves = nil
+	}
+
+	if lastMove := getLastMove(moves); lastMove != nil {
+		lines = append(lines, fmt.Sprintf("Last disk placed at %s", lastMove))
+	}
+	if (m.view == PointConfirmation || m.view == ReplayView || m.view == TutorialView) && len(disksFlipped) > 0 {
+		lines = append(lines, fmt.Sprintf("Disks flipped: %s", formatPoints(disksFlipped)))
+	}
+	if m.view == PointSelection || (m.view == PuzzleView && m.puzzles.attempt == nil) ||
+		(m.view == TutorialView && m.tutorial.isAwaitingMove()) {
+		lines = append(lines, fmt.Sprintf("Available moves: %s", formatPoints(m.availablePoints)))
+	}
+	if m.view == EditorView {
+		lines = append(lines, fmt.Sprintf("Cursor at %s", m.selectedPoint))
+	}
+
+	return strings.Join(lines, "\n")
+}
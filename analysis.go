@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize/english"
+	"io"
+	"strings"
+)
+
+type judgement int
+
+const (
+	GoodMove judgement = iota
+	Inaccuracy
+	Mistake
+	Blunder
+)
+
+func (j judgement) String() string {
+	return [...]string{"Good move", "Inaccuracy", "Mistake", "Blunder"}[j]
+}
+
+func (j judgement) toSymbol() string {
+	return [...]string{"", "?!", "?", "??"}[j]
+}
+
+// Classifies a move by how many disks worse than the best move it is expected to be
+func judgeMove(loss int) judgement {
+	switch {
+	case loss >= 12:
+		return Blunder
+	case loss >= 6:
+		return Mistake
+	case loss >= 2:
+		return Inaccuracy
+	default:
+		return GoodMove
+	}
+}
+
+// Evaluations are the expected final disk differential, from the perspective of the player who made the move
+type moveAnalysis struct {
+	move      moveRecord
+	before    int
+	after     int
+	best      vector2d
+	judgement judgement
+	gridAfter grid
+}
+
+func (a moveAnalysis) String() string {
+	if a.move.pass {
+		return fmt.Sprintf("%s passed", a.move.player)
+	}
+
+	s := fmt.Sprintf("%s: %+d → %+d", a.move.player, a.before, a.after)
+	if a.after < a.before {
+		s += fmt.Sprintf(" (best: %s %+d)", a.best, a.before)
+	}
+	if a.judgement != GoodMove {
+		s += fmt.Sprintf(" %s %s", strings.ToLower(a.judgement.String()), a.judgement.toSymbol())
+	}
+	return s
+}
+
+func analyseGame(gr gameRecord, e engine) []moveAnalysis {
+	states := gr.states()
+	analyses := make([]moveAnalysis, 0, len(gr.moves))
+	for i, mr := range gr.moves {
+		s := states[i]
+		a := moveAnalysis{
+			move:      mr,
+			gridAfter: states[i+1].grid,
+		}
+
+		if !mr.pass {
+			scores := e.evaluateMoves(s)
+
+			a.before = -maxScore - 1
+			for _, p := range s.legalMoves() {
+				if scores[p] > a.before {
+					a.best = p
+					a.before = scores[p]
+				}
+			}
+			a.after = scores[mr.point]
+			a.judgement = judgeMove(a.before - a.after)
+		}
+
+		analyses = append(analyses, a)
+	}
+
+	return analyses
+}
+
+type analysisDoneMsg []moveAnalysis
+
+func analyseGameCmd(gr gameRecord) tea.Cmd {
+	return func() tea.Msg {
+		return analysisDoneMsg(analyseGame(gr, analysisEngine))
+	}
+}
+
+func countJudgements(analyses []moveAnalysis, p player) map[judgement]int {
+	counts := make(map[judgement]int)
+	for _, a := range analyses {
+		if a.move.player == p && !a.move.pass {
+			counts[a.judgement]++
+		}
+	}
+	return counts
+}
+
+func createAnalysisView(m model, maxWidth int) string {
+	t := m.getTheme()
+	textStrings := make([]string, 0, 12)
+
+	if m.analysis == nil {
+		textStrings = append(textStrings, t.accent1Text.Render("Analysis"), "", "Analysing game...")
+	} else if len(m.analysis) == 0 {
+		// The game can end before any moves are made, such as when a player runs out of time
+		textStrings = append(textStrings, t.accent1Text.Render("Analysis"), "", "No moves to analyse")
+	} else {
+		a := m.analysis[m.analysisIndex]
+		textStrings = append(textStrings,
+			t.accent1Text.Render(fmt.Sprintf("Analysis (move %d of %d)", m.analysisIndex+1, len(m.analysis))),
+			"")
+
+		if a.move.pass {
+			textStrings = append(textStrings, fmt.Sprintf("%s (%s) passed", a.move.player, a.move.player.toSymbol()))
+		} else {
+			textStrings = append(textStrings,
+				fmt.Sprintf("%s (%s) played %s", a.move.player, a.move.player.toSymbol(), a.move.point),
+				fmt.Sprintf("Evaluation: %+d before; %+d after", a.before, a.after))
+
+			if a.after < a.before {
+				textStrings = append(textStrings, fmt.Sprintf("Best move: %s (%+d)", a.best, a.before))
+			} else {
+				textStrings = append(textStrings, "Best move played")
+			}
+
+			switch a.judgement {
+			case GoodMove:
+				textStrings = append(textStrings, t.successText.Render(a.judgement.String()))
+			default:
+				textStrings = append(textStrings,
+					t.errorText.Render(fmt.Sprintf("%s (%s)", a.judgement, a.judgement.toSymbol())))
+			}
+		}
+
+		textStrings = append(textStrings, "")
+		for _, p := range []player{DarkPlayer, LightPlayer} {
+			counts := countJudgements(m.analysis, p)
+			textStrings = append(textStrings, fmt.Sprintf("%s: %s; %s; %s", p,
+				english.Plural(counts[Inaccuracy], "inaccuracy", "inaccuracies"),
+				english.Plural(counts[Mistake], "mistake", ""),
+				english.Plural(counts[Blunder], "blunder", "")))
+		}
+		textStrings = append(textStrings, t.secondaryText.Render("Evaluations are the expected final disk differential for the player moving"))
+	}
+
+	textStrings = append(textStrings, "",
+		t.secondaryText.Render(createAnalysisHelp(m.keys)))
+
+	return lipgloss.NewStyle().
+		Width(maxWidth).
+		Render(lipgloss.JoinVertical(lipgloss.Left, textStrings...))
+}
+
+// Annotates the transcript in the given file with the engine's evaluation of each move
+func runAnalyze(args []string, _ settings, w io.Writer) error {
+	flags := newFlagSet("analyze", "[flags] FILE")
+	e := addEngineFlags(flags, analysisEngine)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageError(flags)
+	}
+
+	gr, err := readTranscriptFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	analyses := analyseGame(gr, *e)
+	comments := make([]string, 0, len(analyses))
+	for _, a := range analyses {
+		comments = append(comments, a.String())
+	}
+
+	return writeTranscript(w, gr, comments)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/exp/slices"
+	"time"
+)
+
+type animationSpeed int
+
+const (
+	NoAnimation animationSpeed = iota
+	SlowAnimation
+	NormalAnimation
+	FastAnimation
+)
+
+var allAnimationSpeeds = []animationSpeed{NoAnimation, SlowAnimation, NormalAnimation, FastAnimation}
+
+func (a animationSpeed) String() string {
+	return [...]string{"Off", "Slow", "Normal", "Fast"}[a]
+}
+
+func (a animationSpeed) getFrameDuration() time.Duration {
+	return [...]time.Duration{0, 250 * time.Millisecond, 120 * time.Millisecond, 60 * time.Millisecond}[a]
+}
+
+func toggleAnimationSpeed(a animationSpeed) animationSpeed {
+	return allAnimationSpeeds[(slices.Index(allAnimationSpeeds, a)+1)%len(allAnimationSpeeds)]
+}
+
+type advanceMode int
+
+const (
+	AdvanceOnKeyPress advanceMode = iota
+	AdvanceAutomatically
+)
+
+var allAdvanceModes = []advanceMode{AdvanceOnKeyPress, AdvanceAutomatically}
+
+func (a advanceMode) String() string {
+	return [...]string{"On key press", "Automatically"}[a]
+}
+
+func toggleAdvanceMode(a advanceMode) advanceMode {
+	return allAdvanceModes[(slices.Index(allAdvanceModes, a)+1)%len(allAdvanceModes)]
+}
+
+// How long the PointConfirmation view is shown for, after any animation, when advancing automatically
+const autoAdvanceDelay = time.Second
+
+// Glyphs shown part-way through flipping a disk, as if it's seen edge-on
+var flipGlyphs = [...]string{"|"}
+
+const framesPerDisk = len(flipGlyphs) + 1
+
+// Flips the disks one at a time, in the order they were found by `getPointsToFlip`, i.e. ray by ray outwards from the
+// placed disk
+type flipAnimation struct {
+	disks []vector2d
+	frame int
+	// Used to discard ticks from previous moves
+	id int
+}
+
+type flipAnimationTickMsg int
+
+type autoAdvanceMsg int
+
+func (a flipAnimation) isDone() bool {
+	return a.frame >= len(a.disks)*framesPerDisk
+}
+
+// Returns how far through being flipped the given disk is, from 0 (not flipped yet) to `framesPerDisk` (flipped);
+// disks that aren't being flipped count as already flipped
+func (a flipAnimation) getPhase(p vector2d) int {
+	i := slices.Index(a.disks, p)
+	if i < 0 {
+		return framesPerDisk
+	}
+
+	phase := a.frame - i*framesPerDisk
+	if phase < 0 {
+		return 0
+	} else if phase > framesPerDisk {
+		return framesPerDisk
+	}
+	return phase
+}
+
+// Returns the command for whatever follows the current frame: the next frame, advancing to the next turn, or nothing
+// if waiting for a key press
+func (a flipAnimation) nextCmd(s settings) tea.Cmd {
+	id := a.id
+	if !a.isDone() {
+		return tea.Tick(s.animationSpeed.getFrameDuration(), func(time.Time) tea.Msg {
+			return flipAnimationTickMsg(id)
+		})
+	}
+
+	if s.advanceMode == AdvanceAutomatically {
+		return tea.Tick(autoAdvanceDelay, func(time.Time) tea.Msg {
+			return autoAdvanceMsg(id)
+		})
+	}
+	return nil
+}
+
+// Starts animating the disks flipped by the move just made, skipping straight to the end if animation is off
+func startFlipAnimation(m *model) tea.Cmd {
+	m.flipAnimation = flipAnimation{
+		disks: m.disksFlipped,
+		id:    m.flipAnimation.id + 1,
+	}
+	if m.animationSpeed == NoAnimation || m.displayMode == ScreenReaderDisplay {
+		m.flipAnimation.frame = len(m.disksFlipped) * framesPerDisk
+	}
+
+	return m.flipAnimation.nextCmd(m.settings)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// Which cells of the grid are blocked at the start of the game
+type boardShape int
+
+const (
+	StandardBoard boardShape = iota
+	// The three cells at each corner are blocked, giving an octagonal board
+	OctagonBoard
+	// A few cells are blocked at random, away from the centre
+	RandomHolesBoard
+)
+
+var allBoardShapes = []boardShape{StandardBoard, OctagonBoard, RandomHolesBoard}
+
+func (b boardShape) String() string {
+	return [...]string{"Standard", "Octagon", "Random holes"}[b]
+}
+
+func toggleBoardShape(b boardShape) boardShape {
+	return allBoardShapes[(int(b)+1)%len(allBoardShapes)]
+}
+
+func parseBoardShape(s string) (boardShape, error) {
+	for _, b := range allBoardShapes {
+		if strings.EqualFold(s, b.String()) {
+			return b, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown board %q", s)
+}
+
+const blockedGlyph = "▒"
+
+// Number of holes on a RandomHolesBoard; holes come in pairs on opposite sides of the centre, so that neither player
+// is favoured
+const randomHoleCount = 6
+
+// Blocks the cells that aren't part of the board, using the given source of randomness for random holes
+func addBlockedCells(g *grid, b boardShape, rng *rand.Rand) {
+	switch b {
+	case OctagonBoard:
+		for _, p := range []vector2d{{0, 0}, {1, 0}, {0, 1}} {
+			g[p.y][p.x] = Blocked
+			g[p.y][gridWidth-1-p.x] = Blocked
+			g[gridHeight-1-p.y][p.x] = Blocked
+			g[gridHeight-1-p.y][gridWidth-1-p.x] = Blocked
+		}
+	case RandomHolesBoard:
+		for holes := 0; holes < randomHoleCount; {
+			p := vector2d{rng.Intn(gridWidth), rng.Intn(gridHeight)}
+			// Keep the centre clear so that the opening is played as usual
+			if isPointNearCentre(p) || g[p.y][p.x] == Blocked {
+				continue
+			}
+
+			g[p.y][p.x] = Blocked
+			g[gridHeight-1-p.y][gridWidth-1-p.x] = Blocked
+			holes += 2
+		}
+	}
+}
+
+// Whether the point is within the 4x4 square in the centre of the grid
+func isPointNearCentre(p vector2d) bool {
+	return p.x >= gridWidth/2-2 && p.x < gridWidth/2+2 && p.y >= gridHeight/2-2 && p.y < gridHeight/2+2
+}
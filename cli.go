@@ -0,0 +1,369 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/exp/slices"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+)
+
+type command struct {
+	name        string
+	description string
+	run         func(args []string, s settings, w io.Writer) error
+}
+
+func getCommands() []command {
+	return []command{
+		{"play", "play a game in the terminal (the default)", runPlay},
+		{"replay", "replay a saved game in the terminal", runReplay},
+		{"solve", "find the best move in a position", runSolve},
+		{"analyze", "annotate a saved game with the engine's evaluation of each move", runAnalyze},
+		{"selfplay", "play games between computer players and print their transcripts", runSelfplay},
+		{"convert", "convert a saved game to another format", runConvert},
+		{"puzzles", "solve puzzles from a file, or the built-in puzzles", runPuzzles},
+		{"gen-puzzles", "find puzzles in saved games or games between computer players", runGenPuzzles},
+		{"serve", "serve the engine over an HTTP JSON API", runServe},
+	}
+}
+
+// Returned after printing usage information when a command is used incorrectly
+var errUsage = errors.New("invalid usage")
+
+func newFlagSet(name string, usage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: reversi %s %s\n", name, usage)
+		if hasFlags(flags) {
+			fmt.Fprintln(flags.Output(), "\nFlags:")
+			flags.PrintDefaults()
+		}
+	}
+	return flags
+}
+
+func hasFlags(flags *flag.FlagSet) bool {
+	found := false
+	flags.VisitAll(func(*flag.Flag) {
+		found = true
+	})
+	return found
+}
+
+// Parses the flags; errors other than asking for help have already been reported by the flag package along with the
+// usage information
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	err := flags.Parse(args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		return errUsage
+	}
+	return err
+}
+
+func usageError(flags *flag.FlagSet) error {
+	flags.Usage()
+	return errUsage
+}
+
+func addRulesFlag(flags *flag.FlagSet, s settings) *string {
+	return flags.String("rules", s.rules.String(), "rules to play by (Othello, Reversi, Anti-Reversi, Torus)")
+}
+
+func addEngineFlags(flags *flag.FlagSet, e engine) *engine {
+	flags.IntVar(&e.depth, "depth", e.depth, "number of moves to search ahead")
+	flags.IntVar(&e.exactEmpties, "exact", e.exactEmpties,
+		"number of empty cells at or below which to search to the end of the game, giving exact scores")
+	return &e
+}
+
+// Runs the command given by the arguments, or plays a game if there isn't one
+func run(args []string, s settings, w io.Writer) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runPlay(args, s, w)
+	}
+
+	for _, c := range getCommands() {
+		if c.name == args[0] {
+			return c.run(args[1:], s, w)
+		}
+	}
+
+	printUsage(os.Stderr, nil)
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+func printUsage(w io.Writer, flags *flag.FlagSet) {
+	fmt.Fprintln(w, "Usage: reversi [play] [flags]")
+	fmt.Fprintln(w, "       reversi COMMAND [flags] [arguments]")
+	fmt.Fprintln(w, "\nCommands:")
+	commands := getCommands()
+	nameWidth := 0
+	for _, c := range commands {
+		if len(c.name) > nameWidth {
+			nameWidth = len(c.name)
+		}
+	}
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-*s  %s\n", nameWidth, c.name, c.description)
+	}
+
+	if flags != nil {
+		fmt.Fprintln(w, "\nFlags:")
+		flags.PrintDefaults()
+	}
+	fmt.Fprintln(w, "\nRun \"reversi COMMAND -h\" for help with a command.")
+}
+
+func runProgram(m model) error {
+	// Applied after the config file and flags so that it takes precedence over both; settings are only saved once
+	// they're changed, so it doesn't end up in the config file
+	// The screen reader display doesn't use colour, so it's left alone
+	if isColorDisabled() {
+		m.theme = "Monochrome"
+		if m.displayMode == StandardDisplay {
+			m.displayMode = MarkersDisplay
+		}
+	}
+
+	p := tea.NewProgram(m, tea.WithMouseCellMotion())
+	_, err := p.Run()
+	return err
+}
+
+func runPlay(args []string, s settings, w io.Writer) error {
+	flags := newFlagSet("play", "[flags]")
+	flags.Usage = func() {
+		printUsage(flags.Output(), flags)
+	}
+	// Flags override the settings in the config file for this game only, unless they're changed on the title screen
+	applySettingFlags := addSettingFlags(flags)
+	showVersion := flags.Bool("version", false, "print the version and exit")
+	seed := flags.Int64("seed", -1, fmt.Sprintf(
+		"seed (0–%d) for the random opening or random holes of the first game; implies --opening random if neither is "+
+			"random", maxSeed-1))
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return usageError(flags)
+	}
+
+	if *showVersion {
+		fmt.Fprintf(w, "reversi %s\n", version)
+		return nil
+	}
+
+	if err := applySettingFlags(&s); err != nil {
+		return err
+	}
+
+	if *seed < 0 {
+		return runProgram(createInitialModel(s))
+	}
+	if *seed >= maxSeed {
+		return fmt.Errorf("seed must be less than %d", maxSeed)
+	}
+	// Later games get seeds of their own
+	m := createInitialModel(s)
+	if !needsSeed(m.board, m.opening) {
+		m.opening = RandomOpening
+	}
+	setUpStart(&m, *seed)
+	return runProgram(m)
+}
+
+// Prints the best move in the given position along with its score, or every legal move ordered from best to worst
+func runSolve(args []string, s settings, w io.Writer) error {
+	flags := newFlagSet("solve", "[flags] POSITION")
+	rulesName := addRulesFlag(flags, s)
+	e := addEngineFlags(flags, analysisEngine)
+	showAll := flags.Bool("all", false, "print the score of every legal move")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return usageError(flags)
+	}
+
+	r, err := parseRules(*rulesName)
+	if err != nil {
+		return err
+	}
+
+	// Allow the player to move to be given as a separate argument
+	state, err := parsePosition(strings.Join(flags.Args(), " "), r)
+	if err != nil {
+		return err
+	}
+
+	moves := state.legalMoves()
+	if len(moves) == 0 {
+		if getTurnOutcome(state.grid, state.player, state.rules, state.supply) == GameOver {
+			fmt.Fprintf(w, "Game over (%+d for %s)\n", state.finalScore(), state.player)
+		} else {
+			fmt.Fprintf(w, "%s has no legal moves and must pass\n", state.player)
+		}
+		return nil
+	}
+
+	if !*showAll {
+		p, score := e.bestMove(state)
+		fmt.Fprintf(w, "%s %+d\n", p, score)
+		return nil
+	}
+
+	scores := e.evaluateMoves(state)
+	slices.SortStableFunc(moves, func(a vector2d, b vector2d) bool {
+		return scores[a] > scores[b]
+	})
+	for _, p := range moves {
+		fmt.Fprintf(w, "%s %+d\n", p, scores[p])
+	}
+	return nil
+}
+
+// Plays a game between computer players at the given difficulties, starting with the given number of random moves
+func playSelfplayGame(r rules, playerDifficulties map[player]difficulty, randomMoves int, rng *rand.Rand) (gameRecord, error) {
+	start := newGameState(*newGrid(r), DarkPlayer, r)
+
+	state := start
+	points := make([]vector2d, 0, gridWidth*gridHeight)
+	for moves := state.legalMoves(); len(moves) > 0; moves = state.legalMoves() {
+		var p vector2d
+		if len(points) < randomMoves {
+			p = moves[rng.Intn(len(moves))]
+		} else {
+			p = playerDifficulties[state.player].chooseMove(state)
+		}
+
+		points = append(points, p)
+		state = state.play(p)
+	}
+
+	return replayMoves(start, points)
+}
+
+func runSelfplay(args []string, s settings, w io.Writer) error {
+	flags := newFlagSet("selfplay", "[flags]")
+	rulesName := addRulesFlag(flags, s)
+	games := flags.Int("games", 1, "number of games to play")
+	darkName := flags.String("dark", HardDifficulty.String(), "difficulty of the dark player (Easy, Medium, Hard)")
+	lightName := flags.String("light", HardDifficulty.String(), "difficulty of the light player (Easy, Medium, Hard)")
+	randomMoves := flags.Int("random", 0, "number of random moves at the start of each game, so that games differ")
+	seed := flags.Int64("seed", time.Now().UnixNano(), "seed for the random moves")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return usageError(flags)
+	}
+
+	r, err := parseRules(*rulesName)
+	if err != nil {
+		return err
+	}
+
+	playerDifficulties := make(map[player]difficulty)
+	for p, name := range map[player]string{DarkPlayer: *darkName, LightPlayer: *lightName} {
+		d, err := parseOption(difficulties, name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+		playerDifficulties[p] = d
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	wins := make(map[player]int)
+	ties := 0
+	for i := 0; i < *games; i++ {
+		gr, err := playSelfplayGame(r, playerDifficulties, *randomMoves, rng)
+		if err != nil {
+			return err
+		}
+
+		scores := computeScores(gr.states()[len(gr.moves)].grid)
+		if winner, ok := getLeader(scores, r); ok {
+			wins[winner]++
+		} else {
+			ties++
+		}
+
+		fmt.Fprintf(w, "# Game %d: %s: %d; %s: %d\n", i+1, DarkPlayer, scores[DarkPlayer], LightPlayer,
+			scores[LightPlayer])
+		if err := writeTranscript(w, gr, nil); err != nil {
+			return err
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "# %s (%s) wins: %d; %s (%s) wins: %d; ties: %d\n", DarkPlayer, playerDifficulties[DarkPlayer],
+		wins[DarkPlayer], LightPlayer, playerDifficulties[LightPlayer], wins[LightPlayer], ties)
+	return nil
+}
+
+type gameJSON struct {
+	Rules    string         `json:"rules"`
+	Moves    []string       `json:"moves"`
+	Position string         `json:"position"`
+	Scores   map[string]int `json:"scores"`
+}
+
+func newGameJSON(gr gameRecord) gameJSON {
+	moves := make([]string, 0, len(gr.moves))
+	for _, mr := range gr.moves {
+		if mr.pass {
+			moves = append(moves, "pass")
+		} else {
+			moves = append(moves, mr.point.String())
+		}
+	}
+
+	final := gr.states()[len(gr.moves)]
+	scores := computeScores(final.grid)
+	return gameJSON{
+		Rules:    gr.rules.String(),
+		Moves:    moves,
+		Position: final.String(),
+		Scores: map[string]int{
+			DarkPlayer.toSymbol():  scores[DarkPlayer],
+			LightPlayer.toSymbol(): scores[LightPlayer],
+		},
+	}
+}
+
+func runConvert(args []string, _ settings, w io.Writer) error {
+	flags := newFlagSet("convert", "[flags] FILE")
+	format := flags.String("to", "transcript", "format to convert to (transcript, position, json)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageError(flags)
+	}
+
+	gr, err := readTranscriptFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(*format) {
+	case "transcript":
+		return writeTranscript(w, gr, nil)
+	case "position":
+		_, err := fmt.Fprintln(w, gr.states()[len(gr.moves)])
+		return err
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(newGameJSON(gr))
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dustin/go-humanize/english"
+	"golang.org/x/exp/slices"
+	"strings"
+	"time"
+)
+
+const clockTickInterval = 100 * time.Millisecond
+
+// Clocks showing less than this amount of time are highlighted
+const lowTimeThreshold = 10 * time.Second
+
+type timeControlKind int
+
+const (
+	NoTimeControl timeControlKind = iota
+	SuddenDeath
+	FischerIncrement
+	ByoYomi
+)
+
+type timeControl struct {
+	kind       timeControlKind
+	mainTime   time.Duration
+	increment  time.Duration
+	periods    int
+	periodTime time.Duration
+}
+
+var timeControls = []timeControl{
+	{kind: NoTimeControl},
+	{kind: SuddenDeath, mainTime: 5 * time.Minute},
+	{kind: FischerIncrement, mainTime: 3 * time.Minute, increment: 2 * time.Second},
+	{kind: ByoYomi, mainTime: 5 * time.Minute, periods: 3, periodTime: 30 * time.Second},
+}
+
+func (tc timeControl) String() string {
+	switch tc.kind {
+	case SuddenDeath:
+		return fmt.Sprintf("%.0f min", tc.mainTime.Minutes())
+	case FischerIncrement:
+		return fmt.Sprintf("%.0f min + %.0fs", tc.mainTime.Minutes(), tc.increment.Seconds())
+	case ByoYomi:
+		return fmt.Sprintf("%.0f min + %d×%.0fs", tc.mainTime.Minutes(), tc.periods, tc.periodTime.Seconds())
+	default:
+		return "None"
+	}
+}
+
+func toggleTimeControl(tc timeControl) timeControl {
+	i := slices.Index(timeControls, tc)
+	return timeControls[(i+1)%len(timeControls)]
+}
+
+type gameClock struct {
+	timeControl timeControl
+	remaining   [2]time.Duration
+	periodsLeft [2]int
+	inOvertime  [2]bool
+	lastTick    time.Time
+	// Used to discard ticks from a previous game's clock
+	id      int64
+	flagged player
+}
+
+type clockTickMsg struct {
+	id   int64
+	time time.Time
+}
+
+func newGameClock(tc timeControl) gameClock {
+	c := gameClock{
+		timeControl: tc,
+		flagged:     Blank,
+	}
+
+	for p := range c.remaining {
+		c.remaining[p] = tc.mainTime
+		c.periodsLeft[p] = tc.periods
+	}
+
+	return c
+}
+
+func (c gameClock) isEnabled() bool {
+	return c.timeControl.kind != NoTimeControl
+}
+
+func (c gameClock) isFlagged() bool {
+	return c.flagged != Blank
+}
+
+func (c *gameClock) start(now time.Time) tea.Cmd {
+	c.id = now.UnixNano()
+	c.lastTick = now
+	return c.tick()
+}
+
+func (c gameClock) tick() tea.Cmd {
+	id := c.id
+	return tea.Tick(clockTickInterval, func(t time.Time) tea.Msg {
+		return clockTickMsg{id: id, time: t}
+	})
+}
+
+// Deducts the time elapsed since the last tick from the given player's clock if the clock is running, moving into
+// byo-yomi periods as needed
+// Returns true if the player has run out of time
+func (c *gameClock) update(now time.Time, p player, running bool) bool {
+	elapsed := now.Sub(c.lastTick)
+	c.lastTick = now
+
+	if !running {
+		return false
+	}
+
+	c.remaining[p] -= elapsed
+	for c.remaining[p] <= 0 {
+		if c.timeControl.kind == ByoYomi && !c.inOvertime[p] && c.periodsLeft[p] > 0 {
+			c.inOvertime[p] = true
+		} else if c.timeControl.kind == ByoYomi && c.periodsLeft[p] > 1 {
+			c.periodsLeft[p]--
+		} else {
+			c.remaining[p] = 0
+			c.flagged = p
+			return true
+		}
+
+		c.remaining[p] += c.timeControl.periodTime
+	}
+
+	return false
+}
+
+// Applies the Fischer increment or resets the current byo-yomi period after the given player has moved
+func (c *gameClock) moveMade(p player) {
+	switch c.timeControl.kind {
+	case FischerIncrement:
+		c.remaining[p] += c.timeControl.increment
+	case ByoYomi:
+		if c.inOvertime[p] {
+			c.remaining[p] = c.timeControl.periodTime
+		}
+	}
+}
+
+func formatClockTime(d time.Duration) string {
+	if d < lowTimeThreshold {
+		tenths := d.Truncate(100*time.Millisecond) / (100 * time.Millisecond)
+		return fmt.Sprintf("0:%02d.%d", tenths/10, tenths%10)
+	}
+
+	seconds := int(d.Truncate(time.Second).Seconds())
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+func createClockText(m model) string {
+	t := m.getTheme()
+	clockStrings := make([]string, 0, 2)
+	for _, p := range []player{DarkPlayer, LightPlayer} {
+		// The computer doesn't play on the clock
+		if isComputerPlayer(m.playerMode, p) {
+			continue
+		}
+
+		clockString := formatClockTime(m.clock.remaining[p])
+		if m.clock.inOvertime[p] {
+			clockString += fmt.Sprintf(" (%s left)", english.Plural(m.clock.periodsLeft[p], "period", ""))
+		}
+		if m.clock.remaining[p] < lowTimeThreshold || (m.clock.inOvertime[p] && m.clock.periodsLeft[p] == 1) {
+			clockString = t.errorText.Render(clockString)
+		}
+
+		clockStrings = append(clockStrings, fmt.Sprintf("%s: %s", p.toSymbol(), clockString))
+	}
+
+	return "Time left: " + strings.Join(clockStrings, "; ")
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGameClockUpdate(t *testing.T) {
+	suddenDeath, fischer, byoYomi := timeControls[1], timeControls[2], timeControls[3]
+	tests := []struct {
+		name        string
+		timeControl timeControl
+		// Time taken by dark for each of their moves in turn
+		moves           []time.Duration
+		wantRemaining   time.Duration
+		wantPeriodsLeft int
+		wantOvertime    bool
+		wantFlagged     bool
+	}{
+		{
+			name:          "sudden death",
+			timeControl:   suddenDeath,
+			moves:         []time.Duration{4 * time.Minute, 59 * time.Second},
+			wantRemaining: time.Second,
+		},
+		{
+			name:          "sudden death runs out",
+			timeControl:   suddenDeath,
+			moves:         []time.Duration{4 * time.Minute, time.Minute},
+			wantRemaining: 0,
+			wantFlagged:   true,
+		},
+		{
+			name:          "Fischer increment added after each move",
+			timeControl:   fischer,
+			moves:         []time.Duration{10 * time.Second, 20 * time.Second},
+			wantRemaining: 3*time.Minute - 30*time.Second + 2*2*time.Second,
+		},
+		{
+			name:          "Fischer increment not added once out of time",
+			timeControl:   fischer,
+			moves:         []time.Duration{time.Minute, 2*time.Minute + 3*time.Second},
+			wantRemaining: 0,
+			wantFlagged:   true,
+		},
+		{
+			name:            "byo-yomi main time",
+			timeControl:     byoYomi,
+			moves:           []time.Duration{time.Minute},
+			wantRemaining:   4 * time.Minute,
+			wantPeriodsLeft: 3,
+		},
+		{
+			name:            "byo-yomi period resets after a move",
+			timeControl:     byoYomi,
+			moves:           []time.Duration{5*time.Minute + 10*time.Second, 20 * time.Second},
+			wantRemaining:   30 * time.Second,
+			wantPeriodsLeft: 3,
+			wantOvertime:    true,
+		},
+		{
+			name:            "byo-yomi period used up",
+			timeControl:     byoYomi,
+			moves:           []time.Duration{5*time.Minute + 40*time.Second},
+			wantRemaining:   30 * time.Second,
+			wantPeriodsLeft: 2,
+			wantOvertime:    true,
+		},
+		{
+			name:            "byo-yomi runs out after the last period",
+			timeControl:     byoYomi,
+			moves:           []time.Duration{5*time.Minute + 90*time.Second},
+			wantRemaining:   0,
+			wantPeriodsLeft: 1,
+			wantOvertime:    true,
+			wantFlagged:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newGameClock(tt.timeControl)
+			now := time.Unix(0, 0)
+			c.start(now)
+
+			for _, d := range tt.moves {
+				now = now.Add(d)
+				if c.update(now, DarkPlayer, true) {
+					break
+				}
+				c.moveMade(DarkPlayer)
+			}
+
+			if c.remaining[DarkPlayer] != tt.wantRemaining {
+				t.Errorf("got %s remaining, want %s", c.remaining[DarkPlayer], tt.wantRemaining)
+			}
+			if c.periodsLeft[DarkPlayer] != tt.wantPeriodsLeft {
+				t.Errorf("got %d periods left, want %d", c.periodsLeft[DarkPlayer], tt.wantPeriodsLeft)
+			}
+			if c.inOvertime[DarkPlayer] != tt.wantOvertime {
+				t.Errorf("got overtime %t, want %t", c.inOvertime[DarkPlayer], tt.wantOvertime)
+			}
+			if c.isFlagged() != tt.wantFlagged || (tt.wantFlagged && c.flagged != DarkPlayer) {
+				t.Errorf("got flagged %t, want %t", c.isFlagged(), tt.wantFlagged)
+			}
+			if c.remaining[LightPlayer] != tt.timeControl.mainTime {
+				t.Errorf("got %s remaining for %s, want their clock untouched", c.remaining[LightPlayer], LightPlayer)
+			}
+		})
+	}
+}
+
+func TestGameClockOnlyRunsWhenRunning(t *testing.T) {
+	c := newGameClock(timeControls[1])
+	now := time.Unix(0, 0)
+	c.start(now)
+
+	// Time spent while the clock is paused isn't counted once it's running again
+	now = now.Add(time.Minute)
+	c.update(now, DarkPlayer, false)
+	now = now.Add(10 * time.Second)
+	c.update(now, DarkPlayer, true)
+
+	if want := 5*time.Minute - 10*time.Second; c.remaining[DarkPlayer] != want {
+		t.Errorf("got %s remaining, want %s", c.remaining[DarkPlayer], want)
+	}
+}
+
+func TestFormatClockTime(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 5 * time.Minute, want: "5:00"},
+		{d: 61*time.Second + 500*time.Millisecond, want: "1:01"},
+		{d: 10 * time.Second, want: "0:10"},
+		{d: 9*time.Second + 950*time.Millisecond, want: "0:09.9"},
+		{d: 0, want: "0:00.0"},
+	}
+
+	for _, tt := range tests {
+		if got := formatClockTime(tt.d); got != tt.want {
+			t.Errorf("formatClockTime(%s): got %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// A setting stored in the config file, which can also be set using a command-line flag with the same name
+type configOption struct {
+	name  string
+	usage string
+	get   func(s settings) string
+	set   func(s *settings, value string) error
+}
+
+func newConfigOption[T radioButtonItem](name string, usage string, options []T, get func(s *settings) *T) configOption {
+	optionStrings := make([]string, 0, len(options))
+	for _, option := range options {
+		optionStrings = append(optionStrings, option.String())
+	}
+
+	return configOption{
+		name:  name,
+		usage: fmt.Sprintf("%s (%s)", usage, strings.Join(optionStrings, ", ")),
+		get: func(s settings) string {
+			return (*get(&s)).String()
+		},
+		set: func(s *settings, value string) error {
+			option, err := parseOption(options, value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+
+			*get(s) = option
+			return nil
+		},
+	}
+}
+
+// Returns the option whose name matches the given string, ignoring case
+func parseOption[T radioButtonItem](options []T, s string) (T, error) {
+	optionStrings := make([]string, 0, len(options))
+	for _, option := range options {
+		if strings.EqualFold(s, option.String()) {
+			return option, nil
+		}
+		optionStrings = append(optionStrings, fmt.Sprintf("%q", option.String()))
+	}
+
+	var zero T
+	return zero, fmt.Errorf("unknown value %q; expected one of %s", s, strings.Join(optionStrings, ", "))
+}
+
+// Created on demand as the list of themes isn't known until user themes have been loaded
+func getConfigOptions() []configOption {
+	return []configOption{
+		newConfigOption("rules", "rules to play by", allRules,
+			func(s *settings) *rules { return &s.rules }),
+		newConfigOption("board", "which cells are blocked", allBoardShapes,
+			func(s *settings) *boardShape { return &s.board }),
+		newConfigOption("opening", "whether to start from the usual position or a random opening",
+			allOpeningModes,
+			func(s *settings) *openingMode { return &s.opening }),
+		newConfigOption("mode", "number of human players", []playerMode{OnePlayer, TwoPlayer},
+			func(s *settings) *playerMode { return &s.playerMode }),
+		newConfigOption("difficulty", "how strongly the computer plays",
+			difficulties,
+			func(s *settings) *difficulty { return &s.difficulty }),
+		newConfigOption("time-control", "time control", timeControls,
+			func(s *settings) *timeControl { return &s.timeControl }),
+		newConfigOption("typed-moves", "what happens when a coordinate is typed",
+			[]typedMoveAction{MoveCursorToTypedPoint, PlaceDiskAtTypedPoint},
+			func(s *settings) *typedMoveAction { return &s.typedMoveAction }),
+		newConfigOption("theme", "colour theme", getThemeNames(),
+			func(s *settings) *themeName { return &s.theme }),
+		newConfigOption("display", "display mode",
+			allDisplayModes,
+			func(s *settings) *displayMode { return &s.displayMode }),
+		newConfigOption("animation", "speed of the flip animation",
+			allAnimationSpeeds,
+			func(s *settings) *animationSpeed { return &s.animationSpeed }),
+		newConfigOption("continue", "when to continue after each move",
+			allAdvanceModes,
+			func(s *settings) *advanceMode { return &s.advanceMode }),
+		{
+			name:  "profile",
+			usage: "name of the profile to record statistics under",
+			get: func(s settings) string {
+				return s.profileName
+			},
+			set: func(s *settings, value string) error {
+				if strings.TrimSpace(value) == "" {
+					return errors.New("profile: name must not be blank")
+				}
+
+				s.profileName = strings.TrimSpace(value)
+				return nil
+			},
+		},
+	}
+}
+
+// Key bindings are stored under this name in the config file, as an object mapping each section to an object mapping
+// each binding's name to its list of keys
+const keysConfigName = "keys"
+
+const configFileName = "config.json"
+
+// Held while a file in the game's directory is read, changed and written back, as settings and profiles are saved by
+// commands that can run at the same time
+var dataFilesMutex sync.Mutex
+
+// Returns the path of the file or directory with the given name in the game's directory within the user's config
+// directory, where settings, profiles and themes are kept
+func getDataPath(name string) (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "reversi", name), nil
+}
+
+// Reads the JSON file with the given name in the game's directory into v, leaving v as it is if the file doesn't exist
+func loadDataFile(name string, v any) error {
+	path, err := getDataPath(name)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// Writes v as JSON to the file with the given name in the game's directory, creating the directory if necessary
+// The file is written under a temporary name and then renamed, so that it's never left half-written
+func saveDataFile(name string, v any) error {
+	path, err := getDataPath(name)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(append(data, '\n'))
+	if err == nil {
+		err = f.Chmod(0644)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Applies the settings in the config file to the given settings
+// A missing config file isn't an error, and unknown settings are ignored so that older versions can read newer config
+// files
+func loadSettings(s *settings) error {
+	path, err := getDataPath(configFileName)
+	if err != nil {
+		return err
+	}
+
+	var values map[string]json.RawMessage
+	if err := loadDataFile(configFileName, &values); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, option := range getConfigOptions() {
+		rawValue, ok := values[option.name]
+		if !ok {
+			continue
+		}
+
+		var value string
+		if err := json.Unmarshal(rawValue, &value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", path, option.name, err))
+		} else if err := option.set(s, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+
+	if rawKeys, ok := values[keysConfigName]; ok {
+		var keys map[string]map[string][]string
+		if err := json.Unmarshal(rawKeys, &keys); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", path, keysConfigName, err))
+		} else if err := s.keys.setKeys(keys); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", path, keysConfigName, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Saves the setting with the given name to the config file, leaving the rest of the file as it is so that settings
+// that were only overridden for this game (such as by flags) aren't saved
+func saveSetting(s settings, name string) error {
+	dataFilesMutex.Lock()
+	defer dataFilesMutex.Unlock()
+
+	values := make(map[string]json.RawMessage)
+	if err := loadDataFile(configFileName, &values); err != nil {
+		return err
+	}
+
+	for _, option := range getConfigOptions() {
+		if option.name != name {
+			continue
+		}
+
+		value, err := json.Marshal(option.get(s))
+		if err != nil {
+			return err
+		}
+		values[name] = value
+	}
+
+	return saveDataFile(configFileName, values)
+}
+
+type settingsSavedMsg struct {
+	err error
+}
+
+func saveSettingCmd(s settings, name string) tea.Cmd {
+	return func() tea.Msg {
+		return settingsSavedMsg{err: saveSetting(s, name)}
+	}
+}
+
+// Adds a flag for each setting to the flag set, returning a function that applies the flags that were set
+func addSettingFlags(flags *flag.FlagSet) func(s *settings) error {
+	options := getConfigOptions()
+	values := make([]*string, 0, len(options))
+	for _, option := range options {
+		values = append(values, flags.String(option.name, "", option.usage))
+	}
+
+	return func(s *settings) error {
+		for i, option := range options {
+			if *values[i] == "" {
+				continue
+			}
+
+			if err := option.set(s, *values[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// Points the user's config directory at a new temporary directory for the rest of the test
+func useTempConfigDir(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+}
+
+func writeTestConfig(t *testing.T, data string) {
+	t.Helper()
+	path, err := getDataPath(configFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    func(s *settings)
+		wantErr bool
+	}{
+		{name: "missing file", want: func(*settings) {}},
+		{
+			name:   "settings",
+			config: `{"rules": "reversi", "difficulty": "Hard", "theme": "Classic", "profile": " Ann "}`,
+			want: func(s *settings) {
+				s.rules = ReversiRules
+				s.difficulty = HardDifficulty
+				s.theme = "Classic"
+				s.profileName = "Ann"
+			},
+		},
+		{name: "unknown settings ignored", config: `{"colour": "red"}`, want: func(*settings) {}},
+		{
+			name:    "invalid value",
+			config:  `{"rules": "Chess", "difficulty": "Hard"}`,
+			want:    func(s *settings) { s.difficulty = HardDifficulty },
+			wantErr: true,
+		},
+		{name: "invalid file", config: `{"rules": `, want: func(*settings) {}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTempConfigDir(t)
+			if tt.config != "" {
+				writeTestConfig(t, tt.config)
+			}
+
+			s := defaultSettings
+			err := loadSettings(&s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %t", err, tt.wantErr)
+			}
+
+			want := defaultSettings
+			tt.want(&want)
+			if !reflect.DeepEqual(s, want) {
+				t.Errorf("got settings %+v, want %+v", s, want)
+			}
+		})
+	}
+}
+
+func TestSaveSetting(t *testing.T) {
+	useTempConfigDir(t)
+	writeTestConfig(t, `{"rules": "Reversi", "colour": "red"}`)
+
+	// The rules have been overridden for this game only, so only the difficulty should be saved
+	s := defaultSettings
+	s.rules = AntiReversiRules
+	s.difficulty = MediumDifficulty
+	if err := saveSetting(s, "difficulty"); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := defaultSettings
+	if err := loadSettings(&loaded); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.rules != ReversiRules || loaded.difficulty != MediumDifficulty {
+		t.Errorf("got rules %s and difficulty %s, want %s and %s", loaded.rules, loaded.difficulty, ReversiRules,
+			MediumDifficulty)
+	}
+
+	path, err := getDataPath(configFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values map[string]string
+	if err := json.Unmarshal(data, &values); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"colour": "red", "difficulty": "Medium", "rules": "Reversi"}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("got config file %v, want %v", values, want)
+	}
+}
+
+func TestSaveSettingCreatesFile(t *testing.T) {
+	useTempConfigDir(t)
+
+	s := defaultSettings
+	s.theme = "Light"
+	if err := saveSetting(s, "theme"); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := defaultSettings
+	if err := loadSettings(&loaded); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.theme != "Light" {
+		t.Errorf("got theme %s, want Light", loaded.theme)
+	}
+}
+
+func TestSaveSettingConcurrently(t *testing.T) {
+	useTempConfigDir(t)
+
+	s := defaultSettings
+	s.rules = ReversiRules
+	s.difficulty = HardDifficulty
+	s.theme = "Light"
+	names := []string{"rules", "difficulty", "theme"}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(names))
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			errs[i] = saveSetting(s, name)
+		}(i, name)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded := defaultSettings
+	if err := loadSettings(&loaded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, s) {
+		t.Errorf("got settings %+v, want %+v", loaded, s)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/exp/slices"
+)
+
+type difficulty int
+
+const (
+	EasyDifficulty difficulty = iota
+	MediumDifficulty
+	HardDifficulty
+)
+
+var difficulties = []difficulty{EasyDifficulty, MediumDifficulty, HardDifficulty}
+
+func (d difficulty) String() string {
+	return [...]string{"Easy", "Medium", "Hard"}[d]
+}
+
+func toggleDifficulty(d difficulty) difficulty {
+	return difficulties[(slices.Index(difficulties, d)+1)%len(difficulties)]
+}
+
+// Returns the engine the computer uses at the given difficulty, if any; on easy, the computer just flips as many disks
+// as it can
+func (d difficulty) getEngine() (engine, bool) {
+	switch d {
+	case MediumDifficulty:
+		return engine{depth: 2, exactEmpties: 6}, true
+	case HardDifficulty:
+		return engine{depth: 4, exactEmpties: 12}, true
+	default:
+		return engine{}, false
+	}
+}
+
+// Returns the move the computer would make at the given difficulty
+// There must be at least one legal move
+func (d difficulty) chooseMove(s gameState) vector2d {
+	e, ok := d.getEngine()
+	if !ok {
+		return computeBestPoint(s.grid, s.legalMoves(), s.player, s.rules)
+	}
+
+	p, _ := e.bestMove(s)
+	return p
+}
+
+// Returns the computer's evaluation of each legal move at the given difficulty, from the perspective of the player to
+// move, so that evaluation shading agrees with the hints given at the same difficulty
+func (d difficulty) evaluateMoves(s gameState) map[vector2d]int {
+	e, ok := d.getEngine()
+	if !ok {
+		evaluations := make(map[vector2d]int)
+		for _, p := range s.legalMoves() {
+			evaluations[p] = evaluatePoint(s.grid, p, s.player, s.rules)
+		}
+		return evaluations
+	}
+
+	return e.evaluateMoves(s)
+}
+
+// Sent once the computer has chosen a move, either to play itself or as a hint
+type computerMoveMsg struct {
+	// The position the move was chosen for, so that moves chosen for positions that are no longer on the board (such as
+	// after quitting the game) can be discarded
+	grid   grid
+	player player
+	point  vector2d
+	hint   bool
+}
+
+// Chooses the computer's move in the background, as the engine can take a while on the harder difficulties
+func computeComputerPointCmd(m model, hint bool) tea.Cmd {
+	s := gameState{grid: m.grid, player: m.currentPlayer, rules: m.rules, supply: m.supply}
+	d := m.difficulty
+	return func() tea.Msg {
+		return computerMoveMsg{grid: s.grid, player: s.player, point: d.chooseMove(s), hint: hint}
+	}
+}
+
+// Sent once the computer has evaluated the moves available to the player choosing a move, for evaluation shading
+type evaluationsMsg struct {
+	grid        grid
+	player      player
+	evaluations map[vector2d]int
+}
+
+// Evaluates the available moves in the background, in the same way as computeComputerPointCmd
+func computeEvaluationsCmd(m model) tea.Cmd {
+	s := gameState{grid: m.grid, player: m.currentPlayer, rules: m.rules, supply: m.supply}
+	d := m.difficulty
+	return func() tea.Msg {
+		return evaluationsMsg{grid: s.grid, player: s.player, evaluations: d.evaluateMoves(s)}
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"os"
+	"time"
+)
+
+// The position being set up in the board editor
+type editor struct {
+	state gameState
+	// Result of the last export, if any
+	export positionExportedMsg
+}
+
+func startEditor(m *model) {
+	m.view = EditorView
+	m.editor = editor{state: gameState{grid: m.grid, player: m.currentPlayer, rules: m.rules}}
+}
+
+// Returns the next state in the cycle blank, dark, light
+func cycleCell(cell player) player {
+	switch cell {
+	case Blank:
+		return DarkPlayer
+	case DarkPlayer:
+		return LightPlayer
+	default:
+		return Blank
+	}
+}
+
+func (e *editor) setCell(p vector2d, cell player) {
+	e.state.grid[p.y][p.x] = cell
+}
+
+// Returns the position being edited, with the disk supply worked out from the disks on the board
+func (e editor) getGameState() gameState {
+	return newGameState(e.state.grid, e.state.player, e.state.rules)
+}
+
+// Checks that a game can be played from the position, returning an error describing why not otherwise
+func validatePosition(s gameState) error {
+	switch getTurnOutcome(s.grid, s.player, s.rules, s.supply) {
+	case GameOver:
+		if s.rules == ReversiRules {
+			return fmt.Errorf("%s can't move, so the game would already be over", s.player)
+		}
+		return errors.New("neither player can move, so the game would already be over")
+	case PlayerPasses:
+		return fmt.Errorf("%s can't move, so %s should be to move", s.player, toggleCurrentPlayer(s.player))
+	}
+	return nil
+}
+
+// Starts a game from the position being edited, using the current settings for everything else
+func startEditorGame(m *model) tea.Cmd {
+	s := m.editor.getGameState()
+	if validatePosition(s) != nil {
+		return nil
+	}
+
+	m.grid = s.grid
+	m.currentPlayer = s.player
+	m.supply = s.supply
+	m.availablePoints = s.legalMoves()
+	m.record = newGameRecord(s.grid, s.player, s.rules)
+	m.disksFlipped = nil
+	m.clock = newGameClock(m.timeControl)
+
+	cmd := startTurn(m)
+
+	if m.clock.isEnabled() {
+		return tea.Batch(cmd, m.clock.start(time.Now()))
+	}
+	return cmd
+}
+
+type positionExportedMsg struct {
+	path string
+	err  error
+}
+
+// Writes the position to a file in the current directory, in the format used by `reversi solve`
+func exportPositionCmd(s gameState) tea.Cmd {
+	return func() tea.Msg {
+		path := fmt.Sprintf("reversi-position-%s.txt", time.Now().Format("20060102-150405"))
+
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return positionExportedMsg{err: err}
+		}
+
+		if _, err := fmt.Fprintln(f, s); err != nil {
+			f.Close()
+			return positionExportedMsg{err: err}
+		}
+
+		return positionExportedMsg{path: path, err: f.Close()}
+	}
+}
+
+func createEditorView(m model, maxWidth int) string {
+	t := m.getTheme()
+	s := m.editor.getGameState()
+	scores := computeScores(s.grid)
+
+	textStrings := []string{
+		t.accent1Text.Render("Board editor"),
+		"",
+		fmt.Sprintf("Rules: %s; mode: %s", s.rules, m.playerMode),
+		fmt.Sprintf("%s: %d; %s: %d", DarkPlayer, scores[DarkPlayer], LightPlayer, scores[LightPlayer]),
+		fmt.Sprintf("%s to move", s.player),
+	}
+	if s.rules.hasDiskSupply() {
+		textStrings = append(textStrings, createDiskSupplyText(s.supply))
+	}
+	textStrings = append(textStrings,
+		"",
+		fmt.Sprintf("Position: %s", s),
+		"",
+	)
+
+	if err := validatePosition(s); err != nil {
+		textStrings = append(textStrings, t.errorText.Render(fmt.Sprintf("Cannot start a game: %v", err)))
+	} else {
+		textStrings = append(textStrings, t.successText.Render("Ready to start a game"))
+	}
+
+	if export := m.editor.export; export.err != nil {
+		textStrings = append(textStrings, "", t.errorText.Render(fmt.Sprintf("Could not export position: %v", export.err)))
+	} else if export.path != "" {
+		textStrings = append(textStrings, "", t.successText.Render(fmt.Sprintf("Position exported to %s", export.path)))
+	}
+
+	textStrings = append(textStrings, "", t.secondaryText.Render(createEditorHelp(m.keys)))
+
+	return lipgloss.NewStyle().
+		Width(maxWidth).
+		Render(lipgloss.JoinVertical(lipgloss.Left, textStrings...))
+}
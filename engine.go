@@ -0,0 +1,225 @@
+package main
+
+// A position in a game, along with everything needed to continue the game from it
+type gameState struct {
+	grid   grid
+	player player
+	rules  rules
+	supply diskSupply
+}
+
+// Returns the state for the given position, working out each player's disk supply from the disks on the board
+func newGameState(g grid, p player, r rules) gameState {
+	return gameState{grid: g, player: p, rules: r, supply: newDiskSupply(g, p)}
+}
+
+// Search depth and number of empty cells at which the engine switches to searching to the end of the game, giving an
+// exact result
+type engine struct {
+	depth        int
+	exactEmpties int
+}
+
+var analysisEngine = engine{depth: 4, exactEmpties: 10}
+
+const maxScore = gridWidth * gridHeight
+
+// Weights used by the heuristic evaluation, roughly measured in disks; corners are valuable as they can never be flipped,
+// whereas the cells next to them tend to give the corner away to the opponent
+var cellWeights = [gridHeight][gridWidth]int{
+	{20, -6, 2, 1, 1, 2, -6, 20},
+	{-6, -8, -1, -1, -1, -1, -8, -6},
+	{2, -1, 1, 0, 0, 1, -1, 2},
+	{1, -1, 0, 0, 0, 0, -1, 1},
+	{1, -1, 0, 0, 0, 0, -1, 1},
+	{2, -1, 1, 0, 0, 1, -1, 2},
+	{-6, -8, -1, -1, -1, -1, -8, -6},
+	{20, -6, 2, 1, 1, 2, -6, 20},
+}
+
+// Returns the legal moves for the player to move, in row-major order
+func (s gameState) legalMoves() []vector2d {
+	if s.supply.isExhausted(s.player, s.rules) {
+		return nil
+	}
+	return getAvailablePoints(s.grid, s.player, s.rules)
+}
+
+// Returns the state after the player to move places a disk at the given point, skipping the opponent's turn if they
+// have to pass
+// If the game is over, the returned state has no legal moves
+func (s gameState) play(p vector2d) gameState {
+	next := s
+	next.grid[p.y][p.x] = s.player
+	flip(&next.grid, getPointsToFlip(next.grid, p, s.player, s.rules), s.player)
+	next.supply.use(s.player, s.rules)
+
+	next.player = toggleCurrentPlayer(s.player)
+	if getTurnOutcome(next.grid, next.player, next.rules, next.supply) == PlayerPasses {
+		next.player = s.player
+	}
+
+	return next
+}
+
+func (s gameState) countEmpties() int {
+	empties := 0
+	for _, row := range s.grid {
+		for _, cell := range row {
+			if cell == Blank {
+				empties++
+			}
+		}
+	}
+	return empties
+}
+
+// Returns the final disk differential from the perspective of the player to move, which is negated under Anti-Reversi
+// rules so that a positive score is always a win
+func (s gameState) finalScore() int {
+	scores := computeScores(s.grid)
+	differential := scores[s.player] - scores[toggleCurrentPlayer(s.player)]
+	if s.rules.isMisere() {
+		return -differential
+	}
+	return differential
+}
+
+// Returns a rough estimate of the final score (as given by `finalScore`) from the perspective of the player to move,
+// based on disk positions and mobility
+// Under Anti-Reversi rules, disks in the stable cells that are usually valuable are a liability instead, so the cell
+// weights are negated; mobility is just as useful either way
+// On a torus, there are no corners or edges so every cell is as good as any other; instead, disks next to blank cells
+// count against a player, as they give the opponent moves
+func (s gameState) heuristicScore() int {
+	opponent := toggleCurrentPlayer(s.player)
+	weightSign := 1
+	if s.rules.isMisere() {
+		weightSign = -1
+	} else if s.rules.wraps() {
+		weightSign = 0
+	}
+
+	score := 0
+	for i, row := range s.grid {
+		for j, cell := range row {
+			switch cell {
+			case s.player:
+				score += weightSign * cellWeights[i][j]
+			case opponent:
+				score -= weightSign * cellWeights[i][j]
+			}
+		}
+	}
+
+	score += len(getAvailablePoints(s.grid, s.player, s.rules)) - len(getAvailablePoints(s.grid, opponent, s.rules))
+	if s.rules.wraps() {
+		score -= countFrontierDisks(s.grid, s.player) - countFrontierDisks(s.grid, opponent)
+	}
+
+	if score > maxScore-1 {
+		return maxScore - 1
+	} else if score < -(maxScore - 1) {
+		return -(maxScore - 1)
+	}
+	return score
+}
+
+// Returns the number of the given player's disks that are next to a blank cell, wrapping around the edges of the board
+// These give the opponent moves, so having fewer of them is better
+func countFrontierDisks(g grid, p player) int {
+	count := 0
+	for _, point := range getNonBlankPoints(g) {
+		if g[point.y][point.x] == p && isNextToBlank(g, point) {
+			count++
+		}
+	}
+	return count
+}
+
+func isNextToBlank(g grid, p vector2d) bool {
+	for i := -1; i <= 1; i++ {
+		for j := -1; j <= 1; j++ {
+			neighbor := wrapPoint(vector2d{p.x + j, p.y + i})
+			if g[neighbor.y][neighbor.x] == Blank {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns the score of the given state from the perspective of the player to move, using alpha-beta pruning
+func (e engine) negamax(s gameState, depth int, alpha int, beta int) int {
+	moves := s.legalMoves()
+	if len(moves) == 0 {
+		return s.finalScore()
+	}
+	if depth == 0 {
+		return s.heuristicScore()
+	}
+
+	bestScore := -maxScore - 1
+	for _, p := range moves {
+		score := e.scoreChild(s, s.play(p), depth-1, alpha, beta)
+		if score > bestScore {
+			bestScore = score
+		}
+		if score > alpha {
+			alpha = score
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+
+	return bestScore
+}
+
+// Scores the child state from the perspective of the parent's player to move, who may move again if their opponent
+// had to pass
+func (e engine) scoreChild(parent gameState, child gameState, depth int, alpha int, beta int) int {
+	if child.player == parent.player {
+		return e.negamax(child, depth, alpha, beta)
+	}
+
+	return -e.negamax(child, depth, -beta, -alpha)
+}
+
+func (e engine) searchDepth(s gameState) int {
+	if empties := s.countEmpties(); empties <= e.exactEmpties {
+		return empties
+	}
+
+	return e.depth
+}
+
+// Returns the score of each legal move from the perspective of the player to move
+func (e engine) evaluateMoves(s gameState) map[vector2d]int {
+	depth := e.searchDepth(s)
+
+	scores := make(map[vector2d]int)
+	for _, p := range s.legalMoves() {
+		scores[p] = e.scoreChild(s, s.play(p), depth-1, -maxScore-1, maxScore+1)
+	}
+
+	return scores
+}
+
+// Returns the best move for the player to move, along with its score
+// There must be at least one legal move
+func (e engine) bestMove(s gameState) (vector2d, int) {
+	depth := e.searchDepth(s)
+	alpha := -maxScore - 1
+
+	var bestPoint vector2d
+	for _, p := range s.legalMoves() {
+		score := e.scoreChild(s, s.play(p), depth-1, alpha, maxScore+1)
+		if score > alpha {
+			bestPoint = p
+			alpha = score
+		}
+	}
+
+	return bestPoint, alpha
+}
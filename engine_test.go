@@ -0,0 +1,117 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Returns the exact score of the state from the perspective of the player to move, by searching every line of play
+func solveExhaustively(s gameState) int {
+	moves := s.legalMoves()
+	if len(moves) == 0 {
+		return s.finalScore()
+	}
+
+	bestScore := -maxScore - 1
+	for _, p := range moves {
+		next := s.play(p)
+		score := solveExhaustively(next)
+		if next.player != s.player {
+			score = -score
+		}
+		if score > bestScore {
+			bestScore = score
+		}
+	}
+	return bestScore
+}
+
+func TestBestMoveSolvesKnownEndgame(t *testing.T) {
+	tests := []struct {
+		name      string
+		position  string
+		rules     rules
+		wantMove  string
+		wantScore int
+	}{
+		{
+			// Taking the last cell flips b1, leaving the whole board dark
+			name:      "last move",
+			position:  ".OXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX X",
+			rules:     OthelloRules,
+			wantMove:  "a1",
+			wantScore: 64,
+		},
+		{
+			// Under Anti-Reversi rules, the same move is the worst possible result, but it's the only move
+			name:      "last move under Anti-Reversi rules",
+			position:  ".OXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX X",
+			rules:     AntiReversiRules,
+			wantMove:  "a1",
+			wantScore: -64,
+		},
+		{
+			// Both moves flip one disk, but after a1, light replies with h1 and flips h2, whereas after h1, light has
+			// to pass and dark takes a1 too, leaving light with just h3
+			name: "choosing between two moves",
+			position: `
+				.OXXXXO.
+				XXXXXXXX
+				XXXXXXXO
+				XXXXXXXX
+				XXXXXXXX
+				XXXXXXXX
+				XXXXXXXX
+				XXXXXXXX X`,
+			rules:     OthelloRules,
+			wantMove:  "h1",
+			wantScore: 62,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := parseTestPosition(t, tt.position, tt.rules)
+			p, score := analysisEngine.bestMove(s)
+			if p.String() != tt.wantMove || score != tt.wantScore {
+				t.Errorf("got %s (%+d), want %s (%+d)", p, score, tt.wantMove, tt.wantScore)
+			}
+		})
+	}
+}
+
+// Compares the engine's exact search with an exhaustive search on endgames reached by playing random moves
+func TestExactSearchMatchesExhaustiveSearch(t *testing.T) {
+	const empties = 6
+	e := engine{depth: 1, exactEmpties: empties}
+
+	for _, r := range allRules {
+		for seed := int64(0); seed < 5; seed++ {
+			rng := rand.New(rand.NewSource(seed))
+			s := newStartState(r, StandardBoard, StandardOpening, 0)
+			for s.countEmpties() > empties && len(s.legalMoves()) > 0 {
+				moves := s.legalMoves()
+				s = s.play(moves[rng.Intn(len(moves))])
+			}
+			if len(s.legalMoves()) == 0 {
+				continue
+			}
+
+			want := solveExhaustively(s)
+			if _, got := e.bestMove(s); got != want {
+				t.Errorf("%s, seed %d: got best score %+d, want %+d (position %s)", r, seed, got, want, s)
+			}
+
+			for p, got := range e.evaluateMoves(s) {
+				next := s.play(p)
+				want := solveExhaustively(next)
+				if next.player != s.player {
+					want = -want
+				}
+				if got != want {
+					t.Errorf("%s, seed %d: got %+d for %s, want %+d (position %s)", r, seed, got, p, want, s)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const historyPanelWidth = 10
+
+// The history panel is hidden if the window is narrower than this, to leave enough room for the text
+const minWidthForHistoryPanel = 100
+
+func isHistoryPanelVisible(m model) bool {
+	switch m.view {
+	case PointSelection, PointSelectionComputer, PointConfirmation, PassView, QuitConfirmation, GameOverView:
+		return m.windowSize.x >= minWidthForHistoryPanel
+	default:
+		return false
+	}
+}
+
+// Returns the number of moves that fit in the history panel; the panel can extend below the grid if the window is tall
+// enough
+func getHistoryPanelHeight(m model) int {
+	// Allow for the padding around the view and the panel's title
+	height := m.windowSize.y - 4 - 1
+	if minHeight := gridHeight + 2 - 1; height < minHeight {
+		return minHeight
+	}
+	return height
+}
+
+func getMaxHistoryScroll(m model) int {
+	if maxScroll := len(m.record.moves) - getHistoryPanelHeight(m); maxScroll > 0 {
+		return maxScroll
+	}
+	return 0
+}
+
+// Returns the last move in the record that wasn't a pass, if any
+func getLastMove(moves []moveRecord) *vector2d {
+	for i := len(moves) - 1; i >= 0; i-- {
+		if !moves[i].pass {
+			return &moves[i].point
+		}
+	}
+	return nil
+}
+
+// Creates the list of moves made so far, scrolled up by `historyScroll` moves from the latest move
+func createHistoryPanel(m model) string {
+	t := m.getTheme()
+	height := getHistoryPanelHeight(m)
+
+	end := len(m.record.moves) - m.historyScroll
+	start := end - height
+	if start < 0 {
+		start = 0
+	}
+
+	textStrings := make([]string, 0, height+1)
+	switch {
+	case start > 0 && m.historyScroll > 0:
+		textStrings = append(textStrings, t.accent1Text.Render("Moves ↑↓"))
+	case start > 0:
+		textStrings = append(textStrings, t.accent1Text.Render("Moves ↑"))
+	case m.historyScroll > 0:
+		textStrings = append(textStrings, t.accent1Text.Render("Moves ↓"))
+	default:
+		textStrings = append(textStrings, t.accent1Text.Render("Moves"))
+	}
+
+	for i := start; i < end; i++ {
+		mr := m.record.moves[i]
+		if mr.pass {
+			textStrings = append(textStrings,
+				t.secondaryText.Render(fmt.Sprintf("%2d. %s pass", i+1, mr.player.toSymbol())))
+		} else if i == len(m.record.moves)-1 {
+			textStrings = append(textStrings,
+				t.accent2Text.Render(fmt.Sprintf("%2d. %s %s", i+1, mr.player.toSymbol(), mr.point)))
+		} else {
+			textStrings = append(textStrings, fmt.Sprintf("%2d. %s %s", i+1, mr.player.toSymbol(), mr.point))
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Width(historyPanelWidth).
+		MarginRight(6).
+		Render(lipgloss.JoinVertical(lipgloss.Left, textStrings...))
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Long enough for any valid coordinate plus a character, so that mistakes are shown rather than ignored
+const maxCoordinateInputLength = 4
+
+type typedMoveAction int
+
+const (
+	MoveCursorToTypedPoint typedMoveAction = iota
+	PlaceDiskAtTypedPoint
+)
+
+func (a typedMoveAction) String() string {
+	return [...]string{"Move cursor", "Place disk"}[a]
+}
+
+func toggleTypedMoveAction(a typedMoveAction) typedMoveAction {
+	if a == MoveCursorToTypedPoint {
+		return PlaceDiskAtTypedPoint
+	}
+
+	return MoveCursorToTypedPoint
+}
+
+type coordinateInput struct {
+	active bool
+	text   string
+	err    error
+}
+
+// Handles a key press while the user is typing a coordinate, moving the cursor (and placing a disk if enabled) once
+// a valid coordinate has been entered
+func updateCoordinateInput(m *model, msg tea.KeyMsg) tea.Cmd {
+	km := m.keys.coordinateInput
+	switch {
+	case key.Matches(msg, km.cancel):
+		m.coordinateInput = coordinateInput{}
+	case msg.Type == tea.KeyBackspace:
+		if len(m.coordinateInput.text) > 0 {
+			_, size := utf8.DecodeLastRuneInString(m.coordinateInput.text)
+			m.coordinateInput.text = m.coordinateInput.text[:len(m.coordinateInput.text)-size]
+		}
+		m.coordinateInput.err = nil
+	case key.Matches(msg, km.confirm):
+		p, err := parsePoint(m.coordinateInput.text)
+		if err != nil {
+			m.coordinateInput.err = err
+			return nil
+		}
+
+		m.coordinateInput = coordinateInput{}
+		m.selectedPoint = p
+		if m.typedMoveAction == PlaceDiskAtTypedPoint {
+			return takeTurn(m)
+		}
+	case msg.Type == tea.KeyRunes:
+		for _, r := range msg.Runes {
+			if utf8.RuneCountInString(m.coordinateInput.text) < maxCoordinateInputLength && !unicode.IsSpace(r) {
+				m.coordinateInput.text += string(unicode.ToLower(r))
+			}
+		}
+		m.coordinateInput.err = nil
+	}
+	return nil
+}
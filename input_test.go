@@ -0,0 +1,102 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"testing"
+)
+
+func TestUpdateCoordinateInput(t *testing.T) {
+	typed := func(s string) tea.KeyMsg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+	backspace := tea.KeyMsg{Type: tea.KeyBackspace}
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+	esc := tea.KeyMsg{Type: tea.KeyEsc}
+
+	tests := []struct {
+		name       string
+		action     typedMoveAction
+		keys       []tea.KeyMsg
+		wantText   string
+		wantActive bool
+		wantErr    bool
+		wantPoint  vector2d
+		wantPlaced bool
+	}{
+		{name: "typing", keys: []tea.KeyMsg{typed("F"), typed("5")}, wantText: "f5", wantActive: true},
+		{name: "spaces ignored", keys: []tea.KeyMsg{typed("f 5")}, wantText: "f5", wantActive: true},
+		{name: "length limited", keys: []tea.KeyMsg{typed("abcdef")}, wantText: "abcd", wantActive: true},
+		{name: "backspace", keys: []tea.KeyMsg{typed("f5"), backspace}, wantText: "f", wantActive: true},
+		{name: "backspace when empty", keys: []tea.KeyMsg{backspace}, wantText: "", wantActive: true},
+		{
+			name:       "backspace removes a whole character",
+			keys:       []tea.KeyMsg{typed("f€"), backspace},
+			wantText:   "f",
+			wantActive: true,
+		},
+		{
+			name:       "length limited by characters",
+			keys:       []tea.KeyMsg{typed("éééééé")},
+			wantText:   "éééé",
+			wantActive: true,
+		},
+		{
+			name:       "invalid coordinate",
+			keys:       []tea.KeyMsg{typed("z9"), enter},
+			wantText:   "z9",
+			wantActive: true,
+			wantErr:    true,
+		},
+		{
+			name:       "typing clears error",
+			keys:       []tea.KeyMsg{typed("z9"), enter, backspace, backspace, typed("f")},
+			wantText:   "f",
+			wantActive: true,
+		},
+		{name: "esc cancels", keys: []tea.KeyMsg{typed("f5"), esc}, wantText: ""},
+		{name: "enter moves cursor", keys: []tea.KeyMsg{typed("f5"), enter}, wantPoint: vector2d{5, 4}},
+		{
+			name:       "enter places disk",
+			action:     PlaceDiskAtTypedPoint,
+			keys:       []tea.KeyMsg{typed("f5"), enter},
+			wantPoint:  vector2d{5, 4},
+			wantPlaced: true,
+		},
+		{
+			name:      "enter doesn't place disk where it can't go",
+			action:    PlaceDiskAtTypedPoint,
+			keys:      []tea.KeyMsg{typed("h8"), enter},
+			wantPoint: vector2d{7, 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := createInitialModel(defaultSettings)
+			m.view = PointSelection
+			m.typedMoveAction = tt.action
+			m.selectedPoint = vector2d{}
+			m.coordinateInput = coordinateInput{active: true}
+
+			for _, msg := range tt.keys {
+				updateCoordinateInput(&m, msg)
+			}
+
+			if m.coordinateInput.text != tt.wantText {
+				t.Errorf("got text %q, want %q", m.coordinateInput.text, tt.wantText)
+			}
+			if m.coordinateInput.active != tt.wantActive {
+				t.Errorf("got active %t, want %t", m.coordinateInput.active, tt.wantActive)
+			}
+			if (m.coordinateInput.err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %t", m.coordinateInput.err, tt.wantErr)
+			}
+			if m.selectedPoint != tt.wantPoint {
+				t.Errorf("got cursor at %s, want %s", m.selectedPoint, tt.wantPoint)
+			}
+			if placed := m.view == PointConfirmation; placed != tt.wantPlaced {
+				t.Errorf("got disk placed %t, want %t", placed, tt.wantPlaced)
+			}
+		})
+	}
+}
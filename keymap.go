@@ -0,0 +1,542 @@
+package main
+
+import (
+	"fmt"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+	"strings"
+)
+
+// Symbols used for keys in help text
+var keySymbols = map[string]string{
+	"up":    "↑",
+	"down":  "↓",
+	"left":  "←",
+	"right": "→",
+	" ":     "space",
+}
+
+func formatKey(k string) string {
+	if symbol, ok := keySymbols[k]; ok {
+		return symbol
+	}
+	return k
+}
+
+// Creates a binding whose help text lists all of its keys
+func newBinding(description string, keys ...string) key.Binding {
+	b := key.NewBinding()
+	setBindingKeys(&b, description, keys)
+	return b
+}
+
+func setBindingKeys(b *key.Binding, description string, keys []string) {
+	keyStrings := make([]string, 0, len(keys))
+	for _, k := range keys {
+		keyStrings = append(keyStrings, formatKey(k))
+	}
+
+	b.SetKeys(keys...)
+	b.SetHelp(strings.Join(keyStrings, "/"), description)
+}
+
+type gameKeyMap struct {
+	up          key.Binding
+	down        key.Binding
+	left        key.Binding
+	right       key.Binding
+	place       key.Binding
+	goTo        key.Binding
+	hint        key.Binding
+	evaluation  key.Binding
+	coordinates key.Binding
+	scrollUp    key.Binding
+	scrollDown  key.Binding
+	quit        key.Binding
+}
+
+// Any other keys are typed as part of the coordinate
+type coordinateInputKeyMap struct {
+	confirm key.Binding
+	cancel  key.Binding
+}
+
+type titleKeyMap struct {
+	playerMode  key.Binding
+	difficulty  key.Binding
+	rules       key.Binding
+	board       key.Binding
+	opening     key.Binding
+	timeControl key.Binding
+	typedMoves  key.Binding
+	theme       key.Binding
+	display     key.Binding
+	animation   key.Binding
+	advanceMode key.Binding
+}
+
+type gameOverKeyMap struct {
+	newGame key.Binding
+	analyse key.Binding
+	replay  key.Binding
+	save    key.Binding
+}
+
+// Used for stepping through the moves of a finished game, when analysing or replaying it
+type reviewKeyMap struct {
+	previous key.Binding
+	next     key.Binding
+	first    key.Binding
+	last     key.Binding
+	autoPlay key.Binding
+	faster   key.Binding
+	slower   key.Binding
+	back     key.Binding
+}
+
+type puzzleKeyMap struct {
+	next     key.Binding
+	previous key.Binding
+	retry    key.Binding
+	back     key.Binding
+}
+
+type tutorialKeyMap struct {
+	next     key.Binding
+	previous key.Binding
+	back     key.Binding
+}
+
+// Used for setting up a position in the board editor, along with the game's keys for moving the cursor
+type editorKeyMap struct {
+	cycle      key.Binding
+	dark       key.Binding
+	light      key.Binding
+	blank      key.Binding
+	blocked    key.Binding
+	side       key.Binding
+	clear      key.Binding
+	reset      key.Binding
+	playerMode key.Binding
+	play       key.Binding
+	export     key.Binding
+	back       key.Binding
+}
+
+type keyMap struct {
+	game     gameKeyMap
+	title    titleKeyMap
+	quit     key.Binding
+	gameOver gameOverKeyMap
+	review   reviewKeyMap
+	puzzle   puzzleKeyMap
+	// Used while typing a coordinate after pressing the game's go-to key
+	coordinateInput coordinateInputKeyMap
+	// Named so as not to clash with the binding for starting the tutorial
+	tutorialSteps tutorialKeyMap
+	// Named so as not to clash with the binding for opening the editor
+	editorKeys editorKeyMap
+	stats      key.Binding
+	puzzles    key.Binding
+	tutorial   key.Binding
+	editor     key.Binding
+	help       key.Binding
+}
+
+var defaultKeyMap = keyMap{
+	game: gameKeyMap{
+		up:          newBinding("move up", "up", "w"),
+		down:        newBinding("move down", "down", "s"),
+		left:        newBinding("move left", "left", "a"),
+		right:       newBinding("move right", "right", "d"),
+		place:       newBinding("place disk", "enter", " "),
+		goTo:        newBinding("go to coordinate", "g"),
+		hint:        newBinding("hint", "h"),
+		evaluation:  newBinding("toggle evaluation", "e"),
+		coordinates: newBinding("toggle coordinates", "c"),
+		scrollUp:    newBinding("scroll moves up", "[", "pgup"),
+		scrollDown:  newBinding("scroll moves down", "]", "pgdown"),
+		quit:        newBinding("exit", "q", "ctrl+c"),
+	},
+	coordinateInput: coordinateInputKeyMap{
+		confirm: newBinding("confirm coordinate", "enter"),
+		cancel:  newBinding("cancel", "esc", "ctrl+c"),
+	},
+	title: titleKeyMap{
+		playerMode:  newBinding("toggle player mode", "p"),
+		difficulty:  newBinding("toggle difficulty", "l"),
+		rules:       newBinding("toggle rules", "r"),
+		board:       newBinding("toggle board", "s"),
+		opening:     newBinding("toggle opening", "o"),
+		timeControl: newBinding("toggle time control", "t"),
+		typedMoves:  newBinding("toggle typed moves", "m"),
+		theme:       newBinding("toggle theme", "c"),
+		display:     newBinding("toggle display mode", "d"),
+		animation:   newBinding("toggle flip animation", "a"),
+		advanceMode: newBinding("toggle continue after moves", "n"),
+	},
+	quit: newBinding("quit", "enter"),
+	gameOver: gameOverKeyMap{
+		newGame: newBinding("play again", "enter"),
+		analyse: newBinding("analyse game", "a"),
+		replay:  newBinding("replay game", "v"),
+		save:    newBinding("save game", "s"),
+	},
+	review: reviewKeyMap{
+		previous: newBinding("previous move", "left", "a"),
+		next:     newBinding("next move", "right", "d"),
+		first:    newBinding("first move", "home"),
+		last:     newBinding("last move", "end"),
+		autoPlay: newBinding("auto-play", " "),
+		faster:   newBinding("faster", "+", "="),
+		slower:   newBinding("slower", "-"),
+		back:     newBinding("back", "q", "esc", "ctrl+c"),
+	},
+	puzzle: puzzleKeyMap{
+		next:     newBinding("next puzzle", "n"),
+		previous: newBinding("previous puzzle", "p"),
+		retry:    newBinding("retry", "r"),
+		back:     newBinding("back", "q", "esc", "ctrl+c"),
+	},
+	tutorialSteps: tutorialKeyMap{
+		next:     newBinding("next step", "n"),
+		previous: newBinding("previous step", "p"),
+		back:     newBinding("back", "q", "esc", "ctrl+c"),
+	},
+	editorKeys: editorKeyMap{
+		cycle:      newBinding("cycle cell", "enter", " "),
+		dark:       newBinding("dark disk", "x"),
+		light:      newBinding("light disk", "o"),
+		blank:      newBinding("blank cell", ".", "backspace", "delete"),
+		blocked:    newBinding("blocked cell", "#"),
+		side:       newBinding("toggle player to move", "tab"),
+		clear:      newBinding("clear board", "c"),
+		reset:      newBinding("reset board", "r"),
+		playerMode: newBinding("toggle player mode", "m"),
+		play:       newBinding("start game", "p"),
+		export:     newBinding("export position", "e"),
+		back:       newBinding("back", "q", "esc", "ctrl+c"),
+	},
+	stats:    newBinding("statistics", "i"),
+	puzzles:  newBinding("puzzles", "z"),
+	tutorial: newBinding("tutorial", "u"),
+	editor:   newBinding("board editor", "b"),
+	help:     newBinding("help", "?"),
+}
+
+// Returns every binding by section and name, as used in the config file
+func (km *keyMap) getBindings() map[string]map[string]*key.Binding {
+	return map[string]map[string]*key.Binding{
+		"game": {
+			"up":          &km.game.up,
+			"down":        &km.game.down,
+			"left":        &km.game.left,
+			"right":       &km.game.right,
+			"place":       &km.game.place,
+			"go-to":       &km.game.goTo,
+			"hint":        &km.game.hint,
+			"evaluation":  &km.game.evaluation,
+			"coordinates": &km.game.coordinates,
+			"scroll-up":   &km.game.scrollUp,
+			"scroll-down": &km.game.scrollDown,
+			"quit":        &km.game.quit,
+		},
+		"coordinate-input": {
+			"confirm": &km.coordinateInput.confirm,
+			"cancel":  &km.coordinateInput.cancel,
+		},
+		"title": {
+			"player-mode":  &km.title.playerMode,
+			"difficulty":   &km.title.difficulty,
+			"rules":        &km.title.rules,
+			"board":        &km.title.board,
+			"opening":      &km.title.opening,
+			"time-control": &km.title.timeControl,
+			"typed-moves":  &km.title.typedMoves,
+			"theme":        &km.title.theme,
+			"display":      &km.title.display,
+			"animation":    &km.title.animation,
+			"continue":     &km.title.advanceMode,
+		},
+		"quit-confirmation": {
+			"quit": &km.quit,
+		},
+		"game-over": {
+			"new-game": &km.gameOver.newGame,
+			"analyse":  &km.gameOver.analyse,
+			"replay":   &km.gameOver.replay,
+			"save":     &km.gameOver.save,
+		},
+		"review": {
+			"previous":  &km.review.previous,
+			"next":      &km.review.next,
+			"first":     &km.review.first,
+			"last":      &km.review.last,
+			"auto-play": &km.review.autoPlay,
+			"faster":    &km.review.faster,
+			"slower":    &km.review.slower,
+			"back":      &km.review.back,
+		},
+		"puzzle": {
+			"next":     &km.puzzle.next,
+			"previous": &km.puzzle.previous,
+			"retry":    &km.puzzle.retry,
+			"back":     &km.puzzle.back,
+		},
+		"tutorial": {
+			"next":     &km.tutorialSteps.next,
+			"previous": &km.tutorialSteps.previous,
+			"back":     &km.tutorialSteps.back,
+		},
+		"editor": {
+			"cycle":       &km.editorKeys.cycle,
+			"dark":        &km.editorKeys.dark,
+			"light":       &km.editorKeys.light,
+			"blank":       &km.editorKeys.blank,
+			"blocked":     &km.editorKeys.blocked,
+			"side":        &km.editorKeys.side,
+			"clear":       &km.editorKeys.clear,
+			"reset":       &km.editorKeys.reset,
+			"player-mode": &km.editorKeys.playerMode,
+			"play":        &km.editorKeys.play,
+			"export":      &km.editorKeys.export,
+			"back":        &km.editorKeys.back,
+		},
+		"general": {
+			"stats":    &km.stats,
+			"puzzles":  &km.puzzles,
+			"tutorial": &km.tutorial,
+			"editor":   &km.editor,
+			"help":     &km.help,
+		},
+	}
+}
+
+// Replaces the keys of the given bindings, by section and name
+func (km *keyMap) setKeys(keys map[string]map[string][]string) error {
+	bindings := km.getBindings()
+	for section, sectionKeys := range keys {
+		for name, k := range sectionKeys {
+			b, ok := bindings[section][name]
+			if !ok {
+				return fmt.Errorf("unknown key binding %q", section+"."+name)
+			}
+			if len(k) == 0 {
+				return fmt.Errorf("key binding %q has no keys", section+"."+name)
+			}
+
+			setBindingKeys(b, b.Help().Desc, k)
+		}
+	}
+	return nil
+}
+
+// Returns the first key of each binding, e.g. for describing several related bindings together
+func formatFirstKeys(bindings ...key.Binding) string {
+	keyStrings := make([]string, 0, len(bindings))
+	for _, b := range bindings {
+		if keys := b.Keys(); len(keys) > 0 {
+			keyStrings = append(keyStrings, formatKey(keys[0]))
+		}
+	}
+	return strings.Join(keyStrings, "/")
+}
+
+func formatHelpEntry(keys string, description string) string {
+	return fmt.Sprintf("%s: %s", keys, description)
+}
+
+func formatBindingHelp(b key.Binding) string {
+	return formatHelpEntry(b.Help().Key, b.Help().Desc)
+}
+
+func joinHelpEntries(entries ...string) string {
+	return strings.Join(entries, " • ")
+}
+
+// Describes a binding using only its first key, to keep help lines short
+func formatShortHelp(b key.Binding) string {
+	return formatHelpEntry(formatFirstKeys(b), b.Help().Desc)
+}
+
+func createGameHelp(km keyMap, canPlace bool) string {
+	entries := []string{
+		formatHelpEntry(formatFirstKeys(km.game.up, km.game.down, km.game.left, km.game.right), "move"),
+		formatShortHelp(km.game.goTo),
+	}
+	if canPlace {
+		entries = append(entries, formatShortHelp(km.game.place))
+	}
+	entries = append(entries,
+		formatShortHelp(km.game.hint),
+		formatShortHelp(km.game.evaluation),
+		formatShortHelp(km.game.coordinates),
+		formatHelpEntry(formatFirstKeys(km.game.scrollUp, km.game.scrollDown), "scroll moves"),
+		formatShortHelp(km.game.quit),
+		formatShortHelp(km.help),
+	)
+	return joinHelpEntries(entries...)
+}
+
+func createTitleHelp(km keyMap) string {
+	return joinHelpEntries(
+		formatHelpEntry(formatFirstKeys(km.title.playerMode, km.title.difficulty, km.title.rules, km.title.board,
+			km.title.opening, km.title.timeControl, km.title.typedMoves, km.title.theme, km.title.display,
+			km.title.animation, km.title.advanceMode),
+			"toggle setting"),
+		formatShortHelp(km.stats),
+		formatShortHelp(km.puzzles),
+		formatShortHelp(km.tutorial),
+		formatShortHelp(km.editor),
+		formatShortHelp(km.help),
+		formatHelpEntry("any other key", "continue"),
+	)
+}
+
+func createQuitConfirmationHelp(km keyMap) string {
+	return joinHelpEntries(formatShortHelp(km.quit), formatHelpEntry("any other key", "cancel"))
+}
+
+func createGameOverHelp(km keyMap) string {
+	return joinHelpEntries(
+		formatShortHelp(km.gameOver.newGame),
+		formatShortHelp(km.gameOver.analyse),
+		formatShortHelp(km.gameOver.replay),
+		formatShortHelp(km.gameOver.save),
+		formatShortHelp(km.stats),
+		formatShortHelp(km.help),
+		formatHelpEntry("any other key", "quit"),
+	)
+}
+
+func createAnalysisHelp(km keyMap) string {
+	return joinHelpEntries(
+		formatHelpEntry(formatFirstKeys(km.review.previous, km.review.next), "previous/next move"),
+		formatHelpEntry(formatFirstKeys(km.review.first, km.review.last), "first/last move"),
+		formatShortHelp(km.review.back),
+		formatShortHelp(km.help),
+	)
+}
+
+func createReplayHelp(km keyMap) string {
+	return joinHelpEntries(
+		formatHelpEntry(formatFirstKeys(km.review.previous, km.review.next), "previous/next move"),
+		formatHelpEntry(formatFirstKeys(km.review.first, km.review.last), "start/end"),
+		formatShortHelp(km.review.autoPlay),
+		formatHelpEntry(formatFirstKeys(km.review.faster, km.review.slower), "speed"),
+		formatShortHelp(km.review.back),
+		formatShortHelp(km.help),
+	)
+}
+
+func createPuzzleHelp(km keyMap, canPlace bool) string {
+	entries := make([]string, 0, 7)
+	if canPlace {
+		entries = append(entries,
+			formatHelpEntry(formatFirstKeys(km.game.up, km.game.down, km.game.left, km.game.right), "move"),
+			formatShortHelp(km.game.place))
+	} else {
+		entries = append(entries, formatShortHelp(km.puzzle.retry))
+	}
+	entries = append(entries,
+		formatHelpEntry(formatFirstKeys(km.puzzle.previous, km.puzzle.next), "previous/next puzzle"),
+		formatShortHelp(km.puzzle.back),
+		formatShortHelp(km.help),
+	)
+	return joinHelpEntries(entries...)
+}
+
+func createTutorialHelp(km keyMap, canPlace bool) string {
+	entries := make([]string, 0, 6)
+	if canPlace {
+		entries = append(entries,
+			formatHelpEntry(formatFirstKeys(km.game.up, km.game.down, km.game.left, km.game.right), "move"),
+			formatShortHelp(km.game.place))
+	}
+	entries = append(entries,
+		formatHelpEntry(formatFirstKeys(km.tutorialSteps.previous, km.tutorialSteps.next), "previous/next step"),
+		formatShortHelp(km.tutorialSteps.back),
+		formatShortHelp(km.help),
+	)
+	if !canPlace {
+		entries = append(entries, formatHelpEntry("any other key", "continue"))
+	}
+	return joinHelpEntries(entries...)
+}
+
+func createEditorHelp(km keyMap) string {
+	return joinHelpEntries(
+		formatHelpEntry(formatFirstKeys(km.game.up, km.game.down, km.game.left, km.game.right), "move"),
+		formatShortHelp(km.editorKeys.cycle),
+		formatHelpEntry(formatFirstKeys(km.editorKeys.dark, km.editorKeys.light, km.editorKeys.blank,
+			km.editorKeys.blocked), "set cell"),
+		formatShortHelp(km.editorKeys.side),
+		formatShortHelp(km.editorKeys.clear),
+		formatShortHelp(km.editorKeys.reset),
+		formatShortHelp(km.editorKeys.playerMode),
+		formatShortHelp(km.editorKeys.play),
+		formatShortHelp(km.editorKeys.export),
+		formatShortHelp(km.editorKeys.back),
+		formatShortHelp(km.help),
+	)
+}
+
+// Returns the bindings that can be used in the current view, for the help overlay
+func getViewBindings(m model) []key.Binding {
+	km := m.keys
+	switch m.view {
+	case PointSelection:
+		return []key.Binding{km.game.up, km.game.down, km.game.left, km.game.right, km.game.place, km.game.goTo,
+			km.game.hint, km.game.evaluation, km.game.coordinates, km.game.scrollUp, km.game.scrollDown, km.game.quit}
+	case TitleView:
+		return []key.Binding{km.title.playerMode, km.title.difficulty, km.title.rules, km.title.board,
+			km.title.opening, km.title.timeControl, km.title.typedMoves, km.title.theme, km.title.display,
+			km.title.animation, km.title.advanceMode, km.stats, km.puzzles, km.tutorial, km.editor}
+	case QuitConfirmation:
+		return []key.Binding{km.quit}
+	case GameOverView:
+		return []key.Binding{km.gameOver.newGame, km.gameOver.analyse, km.gameOver.replay, km.gameOver.save, km.stats}
+	case AnalysisView:
+		return []key.Binding{km.review.previous, km.review.next, km.review.first, km.review.last, km.review.back}
+	case ReplayView:
+		return []key.Binding{km.review.previous, km.review.next, km.review.first, km.review.last, km.review.autoPlay,
+			km.review.faster, km.review.slower, km.review.back}
+	case PuzzleView:
+		return []key.Binding{km.game.up, km.game.down, km.game.left, km.game.right, km.game.place, km.puzzle.next,
+			km.puzzle.previous, km.puzzle.retry, km.puzzle.back}
+	case TutorialView:
+		return []key.Binding{km.game.up, km.game.down, km.game.left, km.game.right, km.game.place,
+			km.tutorialSteps.next, km.tutorialSteps.previous, km.tutorialSteps.back}
+	case EditorView:
+		return []key.Binding{km.game.up, km.game.down, km.game.left, km.game.right, km.editorKeys.cycle,
+			km.editorKeys.dark, km.editorKeys.light, km.editorKeys.blank, km.editorKeys.blocked, km.editorKeys.side,
+			km.editorKeys.clear, km.editorKeys.reset, km.editorKeys.playerMode, km.editorKeys.play, km.editorKeys.export,
+			km.editorKeys.back}
+	default:
+		return nil
+	}
+}
+
+// Lists every key that can be used in the current view, shown in place of the usual text
+func createHelpView(m model, maxWidth int) string {
+	t := m.getTheme()
+	bindings := append(getViewBindings(m), m.keys.help)
+
+	keyWidth := 0
+	for _, b := range bindings {
+		if w := lipgloss.Width(b.Help().Key); w > keyWidth {
+			keyWidth = w
+		}
+	}
+
+	textStrings := make([]string, 0, len(bindings)+4)
+	textStrings = append(textStrings, t.accent1Text.Render("Keys"), "")
+	for _, b := range bindings {
+		textStrings = append(textStrings, lipgloss.NewStyle().Width(keyWidth+2).Render(b.Help().Key)+b.Help().Desc)
+	}
+	textStrings = append(textStrings, "", t.secondaryText.Render(formatHelpEntry("any key", "close")))
+
+	return lipgloss.NewStyle().
+		Width(maxWidth).
+		Render(lipgloss.JoinVertical(lipgloss.Left, textStrings...))
+}
@@ -1,13 +1,18 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/dustin/go-humanize/english"
 	"golang.org/x/exp/slices"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 var version = "dev"
@@ -20,12 +25,45 @@ type vector2d struct {
 	y int
 }
 
+// Returns the point in coordinate notation, e.g. "f5", with columns labelled from "a" and rows numbered from 1
+func (v vector2d) String() string {
+	return getColumnLabel(v.x) + getRowLabel(v.y)
+}
+
+func getColumnLabel(x int) string {
+	return string(rune('a' + x))
+}
+
+func getRowLabel(y int) string {
+	return strconv.Itoa(y + 1)
+}
+
+func parsePoint(s string) (vector2d, error) {
+	var column rune
+	var row int
+	if n, err := fmt.Sscanf(strings.ToLower(s), "%c%d", &column, &row); n != 2 || err != nil {
+		return vector2d{}, fmt.Errorf("invalid coordinate %q", s)
+	}
+
+	p := vector2d{int(column - 'a'), row - 1}
+	if p.String() != strings.ToLower(s) {
+		return vector2d{}, fmt.Errorf("invalid coordinate %q", s)
+	}
+	if !isPointInsideGrid(p) {
+		return vector2d{}, fmt.Errorf("coordinate %q is outside the grid", s)
+	}
+
+	return p, nil
+}
+
 type player int
 
 const (
 	DarkPlayer player = iota
 	LightPlayer
 	Blank = -1
+	// A cell that can't hold a disk, which breaks lines of disks like the edge of the grid
+	Blocked = -2
 )
 
 func (p player) String() string {
@@ -36,15 +74,58 @@ func (p player) toSymbol() string {
 	return [...]string{"X", "O"}[p]
 }
 
+// Whether the cell holds a disk, as opposed to being blank or blocked
+func isDisk(cell player) bool {
+	return cell == DarkPlayer || cell == LightPlayer
+}
+
 type rules int
 
 const (
 	ReversiRules rules = iota
 	OthelloRules
+	// Played in the same way as Othello, except that the player with the fewest disks wins
+	AntiReversiRules
+	// Played in the same way as Othello, except that the board wraps around, so lines of disks can continue from one edge
+	// to the opposite edge
+	TorusRules
 )
 
+// Every rules value, in the order they're listed in on the title screen
+var allRules = []rules{OthelloRules, ReversiRules, AntiReversiRules, TorusRules}
+
 func (r rules) String() string {
-	return [...]string{"Reversi", "Othello"}[r]
+	return [...]string{"Reversi", "Othello", "Anti-Reversi", "Torus"}[r]
+}
+
+// Whether the player with the fewest disks wins, rather than the most
+func (r rules) isMisere() bool {
+	return r == AntiReversiRules
+}
+
+// Whether the edges of the board wrap around to the opposite edges
+func (r rules) wraps() bool {
+	return r == TorusRules
+}
+
+// A short summary of how the rules differ from each other, shown on the title screen
+func (r rules) getDescription() string {
+	return [...]string{
+		"The first four disks must be placed in the centre, each player has 32 disks and the game ends as soon as the player to move can't move.",
+		"A player who can't move passes; the game ends when neither player can move and the player with the most disks wins.",
+		"Played like Othello, except that the player with the fewest disks wins.",
+		"Played like Othello, except that the board wraps around, so lines of disks continue from one edge to the opposite edge.",
+	}[r]
+}
+
+func parseRules(s string) (rules, error) {
+	for _, r := range allRules {
+		if strings.EqualFold(s, r.String()) {
+			return r, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown rules %q", s)
 }
 
 type grid [gridHeight][gridWidth]player
@@ -59,6 +140,12 @@ const (
 	QuitConfirmation
 	GameOverView
 	PassView
+	AnalysisView
+	ReplayView
+	StatsView
+	PuzzleView
+	TutorialView
+	EditorView
 )
 
 type playerMode int
@@ -72,16 +159,76 @@ func (pm playerMode) String() string {
 	return [...]string{"1-Player", "2-Player"}[pm]
 }
 
-type model struct {
-	grid            grid
-	selectedPoint   vector2d
-	view            view
-	currentPlayer   player
-	disksFlipped    []vector2d
-	windowSize      vector2d
-	availablePoints []vector2d
+// Options chosen by the user, which are kept when starting a new game
+type settings struct {
 	rules           rules
+	board           boardShape
+	opening         openingMode
 	playerMode      playerMode
+	difficulty      difficulty
+	timeControl     timeControl
+	showCoordinates bool
+	typedMoveAction typedMoveAction
+	theme           themeName
+	displayMode     displayMode
+	animationSpeed  animationSpeed
+	advanceMode     advanceMode
+	keys            keyMap
+	profileName     string
+}
+
+var defaultSettings = settings{
+	rules:           OthelloRules,
+	board:           StandardBoard,
+	opening:         StandardOpening,
+	playerMode:      OnePlayer,
+	difficulty:      EasyDifficulty,
+	timeControl:     timeControls[0],
+	showCoordinates: true,
+	typedMoveAction: MoveCursorToTypedPoint,
+	theme:           "Default",
+	displayMode:     StandardDisplay,
+	animationSpeed:  NoAnimation,
+	advanceMode:     AdvanceOnKeyPress,
+	keys:            defaultKeyMap,
+	profileName:     defaultProfileName,
+}
+
+type model struct {
+	settings
+	grid             grid
+	selectedPoint    vector2d
+	view             view
+	currentPlayer    player
+	supply           diskSupply
+	disksFlipped     []vector2d
+	windowSize       vector2d
+	availablePoints  []vector2d
+	clock            gameClock
+	record           gameRecord
+	hintUsed         bool
+	hintPoint        vector2d
+	hintPending      bool
+	computerThinking bool
+	showEvaluation   bool
+	evaluations      map[vector2d]int
+	analysis         []moveAnalysis
+	analysisIndex    int
+	replay           replay
+	lastSave         gameSavedMsg
+	historyScroll    int
+	coordinateInput  coordinateInput
+	flipAnimation    flipAnimation
+	settingsSaveErr  error
+	showHelp         bool
+	profile          profile
+	profileErr       error
+	computerRatings  computerRatings
+	gameRecorded     bool
+	statsReturnView  view
+	puzzles          puzzleSession
+	tutorial         tutorial
+	editor           editor
 }
 
 func newGrid(r rules) *grid {
@@ -93,7 +240,8 @@ func newGrid(r rules) *grid {
 		}
 	}
 
-	if r == OthelloRules {
+	// Only Reversi starts with an empty board
+	if r != ReversiRules {
 		g[3][3] = LightPlayer
 		g[4][4] = LightPlayer
 		g[3][4] = DarkPlayer
@@ -103,142 +251,471 @@ func newGrid(r rules) *grid {
 	return &g
 }
 
-func createInitialModel(r rules, pm playerMode) model {
-	initialPlayer := DarkPlayer
-	g := *newGrid(r)
+func createInitialModel(s settings) model {
+	m := createModelFromState(s, newGameState(*newGrid(s.rules), DarkPlayer, s.rules))
+	if s.board != StandardBoard || s.opening == RandomOpening {
+		setUpStart(&m, newSeed())
+	}
+	return m
+}
 
+// Returns a model for a game that has reached the given state, without setting up the start given by the settings
+func createModelFromState(s settings, state gameState) model {
 	return model{
-		grid:            g,
+		settings:        s,
+		grid:            state.grid,
 		selectedPoint:   vector2d{3, 3},
 		view:            TitleView,
-		currentPlayer:   initialPlayer,
+		currentPlayer:   state.player,
+		supply:          state.supply,
 		disksFlipped:    make([]vector2d, 0),
-		availablePoints: getAvailablePoints(g, initialPlayer, r),
-		rules:           r,
-		playerMode:      pm,
+		availablePoints: state.legalMoves(),
+		clock:           newGameClock(s.timeControl),
+		record:          newGameRecord(state.grid, state.player, state.rules),
 	}
 }
 
-func initialModel() model {
-	return createInitialModel(OthelloRules, OnePlayer)
+// Returns a model for a new game using the current settings, keeping track of the window size
+func resetModel(m model) model {
+	newModel := createInitialModel(m.settings)
+	newModel.windowSize = m.windowSize
+	newModel.profile = m.profile
+	newModel.computerRatings = m.computerRatings
+	return newModel
 }
 
+// Loads the profile so that its rating can be shown on the title screen
 func (m model) Init() tea.Cmd {
-	return nil
+	return loadProfileCmd(m.profileName)
 }
 
-func isComputerTurn(m model) bool {
-	if m.playerMode == OnePlayer && m.currentPlayer == LightPlayer {
+func isComputerPlayer(pm playerMode, p player) bool {
+	if pm == OnePlayer && p == LightPlayer {
 		return true
 	}
 
 	return false
 }
 
+func isComputerTurn(m model) bool {
+	return isComputerPlayer(m.playerMode, m.currentPlayer)
+}
+
 func flipSelectedPoint(m *model) {
 	m.grid[m.selectedPoint.y][m.selectedPoint.x] = m.currentPlayer
 }
 
-func takeTurn(m *model) {
+func takeTurn(m *model) tea.Cmd {
 	if slices.Contains(m.availablePoints, m.selectedPoint) {
 		flipSelectedPoint(m)
 
-		pointsToFlip := getPointsToFlip(m.grid, m.selectedPoint, m.currentPlayer)
+		pointsToFlip := getPointsToFlip(m.grid, m.selectedPoint, m.currentPlayer, m.rules)
 		flip(&m.grid, pointsToFlip, m.currentPlayer)
 		m.disksFlipped = pointsToFlip
+		m.supply.use(m.currentPlayer, m.rules)
+
+		if isComputerTurn(*m) {
+			m.record.addMove(m.currentPlayer, m.selectedPoint, false)
+		} else {
+			m.record.addMove(m.currentPlayer, m.selectedPoint, m.hintUsed || m.showEvaluation)
+			m.clock.moveMade(m.currentPlayer)
+		}
+		m.hintUsed = false
+		m.hintPending = false
 
 		m.view = PointConfirmation
+		return startFlipAnimation(m)
+	}
+	return nil
+}
+
+// Moves on to the next player's turn after the PointConfirmation view
+func endTurn(m *model) tea.Cmd {
+	// Update current player *after* displaying PointConfirmation view
+	m.currentPlayer = toggleCurrentPlayer(m.currentPlayer)
+
+	// Update available points
+	m.availablePoints = getAvailablePoints(m.grid, m.currentPlayer, m.rules)
+
+	switch getTurnOutcome(m.grid, m.currentPlayer, m.rules, m.supply) {
+	case GameOver:
+		return endGame(m)
+	case PlayerPasses:
+		m.view = PassView
+	default:
+		return startTurn(m)
+	}
+	return nil
+}
+
+// Lets the current player choose their move, or starts choosing it for them if they're a computer player
+func startTurn(m *model) tea.Cmd {
+	if isComputerTurn(*m) {
+		m.view = PointSelectionComputer
+		m.computerThinking = true
+		return computeComputerPointCmd(*m, false)
+	}
+
+	m.view = PointSelection
+	m.evaluations = nil
+	if m.showEvaluation {
+		return computeEvaluationsCmd(*m)
 	}
+	return nil
+}
+
+// Skips the current player's turn after the PassView view
+func passTurn(m *model) tea.Cmd {
+	m.record.addPass(m.currentPlayer)
+	m.currentPlayer = toggleCurrentPlayer(m.currentPlayer)
+	m.availablePoints = getAvailablePoints(m.grid, m.currentPlayer, m.rules)
+	return startTurn(m)
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.view == PointSelection && m.coordinateInput.active {
+			return m, updateCoordinateInput(&m, msg)
+		}
+
+		// Any key closes the help overlay
+		if m.showHelp {
+			m.showHelp = false
+			return m, nil
+		}
+		// Views without bindings of their own continue on any key, including the help key
+		if key.Matches(msg, m.keys.help) && getViewBindings(m) != nil {
+			m.showHelp = true
+			return m, nil
+		}
+
+		km := m.keys
 		switch m.view {
 		case PointSelection:
-			switch msg.String() {
-			case "ctrl+c", "q":
+			switch {
+			case key.Matches(msg, km.game.quit):
 				m.view = QuitConfirmation
-			case "up", "w":
-				m.selectedPoint.y--
-				m.selectedPoint.y = (m.selectedPoint.y + gridHeight) % gridHeight
-			case "down", "s":
-				m.selectedPoint.y++
-				m.selectedPoint.y = (m.selectedPoint.y + gridHeight) % gridHeight
-			case "left", "a":
-				m.selectedPoint.x--
-				m.selectedPoint.x = (m.selectedPoint.x + gridWidth) % gridWidth
-			case "right", "d":
-				m.selectedPoint.x++
-				m.selectedPoint.x = (m.selectedPoint.x + gridWidth) % gridWidth
-			case "enter", " ":
-				takeTurn(&m)
-			}
-		case PointSelectionComputer:
-			takeTurn(&m)
-		case PointConfirmation:
-			// Update current player *after* displaying PointConfirmation view
-			m.currentPlayer = toggleCurrentPlayer(m.currentPlayer)
-
-			// Update available points
-			availablePointsByPlayer := make(map[player][]vector2d)
-			availablePointsByPlayer[DarkPlayer] = getAvailablePoints(m.grid, DarkPlayer, m.rules)
-			availablePointsByPlayer[LightPlayer] = getAvailablePoints(m.grid, LightPlayer, m.rules)
-			m.availablePoints = availablePointsByPlayer[m.currentPlayer]
-
-			// If no available moves for current player then it's game over (for Reversi) or skip turn (for Othello)
-			// If no available moves for either player then it's game over
-			// Otherwise continue game and switch to PointSelection view
-			playersCanMove := make(map[player]bool)
-			playersCanMove[DarkPlayer] = len(availablePointsByPlayer[DarkPlayer]) > 0
-			playersCanMove[LightPlayer] = len(availablePointsByPlayer[LightPlayer]) > 0
-
-			if !playersCanMove[DarkPlayer] && !playersCanMove[LightPlayer] {
-				m.view = GameOverView
-			} else if !playersCanMove[m.currentPlayer] {
-				if m.rules == ReversiRules {
-					m.view = GameOverView
-				} else {
-					m.view = PassView
+			case moveCursor(&m.selectedPoint, msg, km.game):
+			case key.Matches(msg, km.game.place):
+				return m, takeTurn(&m)
+			case key.Matches(msg, km.game.hint):
+				if !m.hintPending {
+					m.hintPending = true
+					return m, computeComputerPointCmd(m, true)
 				}
-			} else {
-				if isComputerTurn(m) {
-					m.view = PointSelectionComputer
-					m.selectedPoint = computeBestPoint(m)
-					flipSelectedPoint(&m)
-				} else {
-					m.view = PointSelection
+			case key.Matches(msg, km.game.evaluation):
+				m.showEvaluation = !m.showEvaluation
+				if m.showEvaluation && m.evaluations == nil {
+					return m, computeEvaluationsCmd(m)
+				}
+			case key.Matches(msg, km.game.coordinates):
+				m.showCoordinates = !m.showCoordinates
+			case key.Matches(msg, km.game.goTo):
+				m.coordinateInput = coordinateInput{active: true}
+			case key.Matches(msg, km.game.scrollUp):
+				if m.historyScroll < getMaxHistoryScroll(m) {
+					m.historyScroll++
+				}
+			case key.Matches(msg, km.game.scrollDown):
+				if m.historyScroll > 0 {
+					m.historyScroll--
 				}
 			}
+		case PointSelectionComputer:
+			if m.computerThinking {
+				return m, nil
+			}
+			return m, takeTurn(&m)
+		case PointConfirmation:
+			return m, endTurn(&m)
 		case TitleView:
-			switch msg.String() {
-			case "r":
+			switch {
+			case key.Matches(msg, km.stats):
+				return m, openStatsView(&m)
+			case key.Matches(msg, km.puzzles):
+				startPuzzles(&m, builtInPuzzles)
+				return m, nil
+			case key.Matches(msg, km.tutorial):
+				startTutorial(&m)
+				return m, nil
+			case key.Matches(msg, km.editor):
+				startEditor(&m)
+				return m, nil
+			case key.Matches(msg, km.title.rules):
 				m.rules = toggleRules(m.rules)
-				return createInitialModel(m.rules, m.playerMode), nil
-			case "p":
+				return resetModel(m), saveSettingCmd(m.settings, "rules")
+			case key.Matches(msg, km.title.board):
+				m.board = toggleBoardShape(m.board)
+				return resetModel(m), saveSettingCmd(m.settings, "board")
+			case key.Matches(msg, km.title.opening):
+				m.opening = toggleOpeningMode(m.opening)
+				return resetModel(m), saveSettingCmd(m.settings, "opening")
+			case key.Matches(msg, km.title.playerMode):
 				m.playerMode = togglePlayerMode(m.playerMode)
+				return m, saveSettingCmd(m.settings, "mode")
+			case key.Matches(msg, km.title.difficulty):
+				m.difficulty = toggleDifficulty(m.difficulty)
+				return m, saveSettingCmd(m.settings, "difficulty")
+			case key.Matches(msg, km.title.timeControl):
+				m.timeControl = toggleTimeControl(m.timeControl)
+				m.clock = newGameClock(m.timeControl)
+				return m, saveSettingCmd(m.settings, "time-control")
+			case key.Matches(msg, km.title.typedMoves):
+				m.typedMoveAction = toggleTypedMoveAction(m.typedMoveAction)
+				return m, saveSettingCmd(m.settings, "typed-moves")
+			case key.Matches(msg, km.title.theme):
+				m.theme = toggleTheme(m.theme)
+				return m, saveSettingCmd(m.settings, "theme")
+			case key.Matches(msg, km.title.display):
+				m.displayMode = toggleDisplayMode(m.displayMode)
+				return m, saveSettingCmd(m.settings, "display")
+			case key.Matches(msg, km.title.animation):
+				m.animationSpeed = toggleAnimationSpeed(m.animationSpeed)
+				return m, saveSettingCmd(m.settings, "animation")
+			case key.Matches(msg, km.title.advanceMode):
+				m.advanceMode = toggleAdvanceMode(m.advanceMode)
+				return m, saveSettingCmd(m.settings, "continue")
 			default:
-				m.view = PointSelection
+				// A random opening may leave the computer to move first
+				cmd := startTurn(&m)
+
+				if m.clock.isEnabled() {
+					return m, tea.Batch(cmd, m.clock.start(time.Now()))
+				}
+				return m, cmd
 			}
 		case QuitConfirmation:
-			switch msg.String() {
-			case "enter":
+			switch {
+			case key.Matches(msg, km.quit):
 				return m, tea.Quit
 			default:
 				m.view = PointSelection
 			}
 		case GameOverView:
-			switch msg.String() {
-			case "enter":
-				return createInitialModel(m.rules, m.playerMode), nil
+			switch {
+			case key.Matches(msg, km.gameOver.newGame):
+				return resetModel(m), nil
+			case key.Matches(msg, km.gameOver.analyse):
+				m.view = AnalysisView
+				if m.analysis == nil {
+					return m, analyseGameCmd(m.record)
+				}
+			case key.Matches(msg, km.gameOver.replay):
+				m.view = ReplayView
+				m.replay = newReplay(m.record)
+			case key.Matches(msg, km.gameOver.save):
+				return m, saveGameCmd(m.record)
+			case key.Matches(msg, km.stats):
+				return m, openStatsView(&m)
 			default:
 				return m, tea.Quit
 			}
+		case ReplayView:
+			switch {
+			case key.Matches(msg, km.review.back):
+				m.replay.stopAutoPlay()
+				m.view = GameOverView
+			case key.Matches(msg, km.review.previous):
+				m.replay.stopAutoPlay()
+				if m.replay.index > 0 {
+					m.replay.index--
+				}
+			case key.Matches(msg, km.review.next):
+				m.replay.stopAutoPlay()
+				if !m.replay.isAtEnd() {
+					m.replay.index++
+				}
+			case key.Matches(msg, km.review.first):
+				m.replay.stopAutoPlay()
+				m.replay.index = 0
+			case key.Matches(msg, km.review.last):
+				m.replay.stopAutoPlay()
+				m.replay.index = len(m.replay.states) - 1
+			case key.Matches(msg, km.review.autoPlay):
+				return m, m.replay.toggleAutoPlay()
+			case key.Matches(msg, km.review.faster):
+				if m.replay.speed < len(replaySpeeds)-1 {
+					m.replay.speed++
+				}
+			case key.Matches(msg, km.review.slower):
+				if m.replay.speed > 0 {
+					m.replay.speed--
+				}
+			}
+		case AnalysisView:
+			switch {
+			case key.Matches(msg, km.review.back):
+				m.view = GameOverView
+			case key.Matches(msg, km.review.previous):
+				if m.analysisIndex > 0 {
+					m.analysisIndex--
+				}
+			case key.Matches(msg, km.review.next):
+				if m.analysisIndex < len(m.analysis)-1 {
+					m.analysisIndex++
+				}
+			case key.Matches(msg, km.review.first):
+				m.analysisIndex = 0
+			case key.Matches(msg, km.review.last):
+				if len(m.analysis) > 0 {
+					m.analysisIndex = len(m.analysis) - 1
+				}
+			}
 		case PassView:
-			m.currentPlayer = toggleCurrentPlayer(m.currentPlayer)
-			m.view = PointSelection
-			m.availablePoints = getAvailablePoints(m.grid, m.currentPlayer, m.rules)
+			return m, passTurn(&m)
+		case StatsView:
+			m.view = m.statsReturnView
+		case PuzzleView:
+			switch {
+			case key.Matches(msg, km.puzzle.back):
+				return resetModel(m), nil
+			case moveCursor(&m.selectedPoint, msg, km.game):
+			case key.Matches(msg, km.game.place):
+				return m, attemptPuzzle(&m)
+			case key.Matches(msg, km.puzzle.retry):
+				showPuzzle(&m)
+			case key.Matches(msg, km.puzzle.next):
+				if m.puzzles.index < len(m.puzzles.puzzles)-1 {
+					m.puzzles.index++
+					showPuzzle(&m)
+				}
+			case key.Matches(msg, km.puzzle.previous):
+				if m.puzzles.index > 0 {
+					m.puzzles.index--
+					showPuzzle(&m)
+				}
+			}
+		case TutorialView:
+			switch {
+			case key.Matches(msg, km.tutorialSteps.back):
+				return resetModel(m), nil
+			case key.Matches(msg, km.tutorialSteps.previous):
+				if m.tutorial.index > 0 {
+					showTutorialStep(&m, m.tutorial.index-1)
+				}
+			case key.Matches(msg, km.tutorialSteps.next):
+				advanceTutorial(&m)
+			case !m.tutorial.isAwaitingMove():
+				advanceTutorial(&m)
+			case moveCursor(&m.selectedPoint, msg, km.game):
+				m.tutorial.wrongMove = false
+			case key.Matches(msg, km.game.place):
+				makeTutorialMove(&m)
+			}
+		case EditorView:
+			switch {
+			case key.Matches(msg, km.editorKeys.back):
+				return resetModel(m), nil
+			case moveCursor(&m.selectedPoint, msg, km.game):
+			case key.Matches(msg, km.editorKeys.cycle):
+				m.editor.setCell(m.selectedPoint, cycleCell(m.editor.state.grid[m.selectedPoint.y][m.selectedPoint.x]))
+			case key.Matches(msg, km.editorKeys.dark):
+				m.editor.setCell(m.selectedPoint, DarkPlayer)
+			case key.Matches(msg, km.editorKeys.light):
+				m.editor.setCell(m.selectedPoint, LightPlayer)
+			case key.Matches(msg, km.editorKeys.blank):
+				m.editor.setCell(m.selectedPoint, Blank)
+			case key.Matches(msg, km.editorKeys.blocked):
+				m.editor.setCell(m.selectedPoint, Blocked)
+			case key.Matches(msg, km.editorKeys.side):
+				m.editor.state.player = toggleCurrentPlayer(m.editor.state.player)
+			case key.Matches(msg, km.editorKeys.clear):
+				// Reversi games start with an empty board
+				m.editor.state.grid = *newGrid(ReversiRules)
+			case key.Matches(msg, km.editorKeys.reset):
+				m.editor.state.grid = *newGrid(m.rules)
+				m.editor.state.player = DarkPlayer
+			case key.Matches(msg, km.editorKeys.playerMode):
+				m.playerMode = togglePlayerMode(m.playerMode)
+				return m, saveSettingCmd(m.settings, "mode")
+			case key.Matches(msg, km.editorKeys.play):
+				return m, startEditorGame(&m)
+			case key.Matches(msg, km.editorKeys.export):
+				return m, exportPositionCmd(m.editor.state)
+			}
+		}
+	case tea.MouseMsg:
+		return updateMouse(m, msg)
+	case flipAnimationTickMsg:
+		// Discard ticks from previous moves, including once the player has continued without waiting for the animation
+		if int(msg) != m.flipAnimation.id || m.view != PointConfirmation {
+			return m, nil
+		}
+
+		m.flipAnimation.frame++
+		return m, m.flipAnimation.nextCmd(m.settings)
+	case autoAdvanceMsg:
+		if int(msg) != m.flipAnimation.id || m.view != PointConfirmation {
+			return m, nil
 		}
+
+		return m, endTurn(&m)
+	case replayTickMsg:
+		// Discard ticks from before auto-play was stopped or restarted
+		if int(msg) != m.replay.tickID || !m.replay.autoPlay || m.view != ReplayView {
+			return m, nil
+		}
+
+		m.replay.index++
+		if m.replay.isAtEnd() {
+			m.replay.stopAutoPlay()
+			return m, nil
+		}
+
+		return m, m.replay.tick()
+	case settingsSavedMsg:
+		m.settingsSaveErr = msg.err
+	case gameSavedMsg:
+		m.lastSave = msg
+	case positionExportedMsg:
+		m.editor.export = msg
+	case profileLoadedMsg:
+		m.profileErr = msg.err
+		if msg.err == nil {
+			m.profile = msg.profile
+		}
+		if msg.computerRatings != nil {
+			m.computerRatings = msg.computerRatings
+		}
+		m.gameRecorded = m.gameRecorded || msg.gameRecorded
+	case puzzleCheckedMsg:
+		return m, updatePuzzleChecked(&m, msg)
+	case computerMoveMsg:
+		if msg.grid != m.grid || msg.player != m.currentPlayer {
+			return m, nil
+		}
+
+		if msg.hint && m.view == PointSelection && m.hintPending {
+			m.hintPending = false
+			m.selectedPoint = msg.point
+			m.hintPoint = msg.point
+			m.hintUsed = true
+		} else if !msg.hint && m.view == PointSelectionComputer && m.computerThinking {
+			m.computerThinking = false
+			m.selectedPoint = msg.point
+			flipSelectedPoint(&m)
+		}
+	case evaluationsMsg:
+		if msg.grid == m.grid && msg.player == m.currentPlayer && m.view == PointSelection {
+			m.evaluations = msg.evaluations
+		}
+	case analysisDoneMsg:
+		m.analysis = msg
+		m.analysisIndex = 0
+	case clockTickMsg:
+		// Discard ticks from previous games and stop ticking once the game is over
+		if msg.id != m.clock.id || m.view == GameOverView {
+			return m, nil
+		}
+
+		// Clock only runs while a human player is choosing a move, so it's paused on the QuitConfirmation and PassView
+		// views, for example
+		if m.clock.update(msg.time, m.currentPlayer, m.view == PointSelection) {
+			return m, endGame(&m)
+		}
+
+		return m, m.clock.tick()
 	case tea.WindowSizeMsg:
 		m.windowSize = vector2d{
 			x: msg.Width,
@@ -249,21 +726,56 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func computeBestPoint(m model) vector2d {
+func computeBestPoint(g grid, availablePoints []vector2d, currentPlayer player, r rules) vector2d {
 	var bestPoint vector2d
-	maxFlippedPointsCount := -1 // Initialising to -1 so `bestPoint` is always assigned even if `flippedPointsCount` is 0
+	maxEvaluation := 0
 
-	for _, p := range m.availablePoints {
-		flippedPointsCount := len(getPointsToFlip(m.grid, p, m.currentPlayer))
-		if flippedPointsCount > maxFlippedPointsCount {
+	for i, p := range availablePoints {
+		evaluation := evaluatePoint(g, p, currentPlayer, r)
+		if i == 0 || evaluation > maxEvaluation {
 			bestPoint = p
-			maxFlippedPointsCount = flippedPointsCount
+			maxEvaluation = evaluation
 		}
 	}
 
 	return bestPoint
 }
 
+// Evaluates the given point from the perspective of the given player; currently this is just the number of disks
+// that would be flipped, or minus that number under Anti-Reversi rules where flipping fewer disks is better
+func evaluatePoint(g grid, p vector2d, currentPlayer player, r rules) int {
+	if r.isMisere() {
+		return -len(getPointsToFlip(g, p, currentPlayer, r))
+	}
+	return len(getPointsToFlip(g, p, currentPlayer, r))
+}
+
+// Assigns each evaluated point a shade (an index into the theme's evaluation styles) according to its evaluation
+// relative to the other points, with the best points getting the brightest shade
+func computeEvaluationShades(evaluations map[vector2d]int, shadeCount int) map[vector2d]int {
+	minEvaluation, maxEvaluation := 0, 0
+	first := true
+	for _, evaluation := range evaluations {
+		if first || evaluation < minEvaluation {
+			minEvaluation = evaluation
+		}
+		if first || evaluation > maxEvaluation {
+			maxEvaluation = evaluation
+		}
+		first = false
+	}
+
+	shades := make(map[vector2d]int, len(evaluations))
+	for p, evaluation := range evaluations {
+		if maxEvaluation == minEvaluation {
+			shades[p] = shadeCount - 1
+		} else {
+			shades[p] = (evaluation - minEvaluation) * (shadeCount - 1) / (maxEvaluation - minEvaluation)
+		}
+	}
+	return shades
+}
+
 func toggleCurrentPlayer(currentPlayer player) player {
 	if currentPlayer == DarkPlayer {
 		return LightPlayer
@@ -272,12 +784,9 @@ func toggleCurrentPlayer(currentPlayer player) player {
 	return DarkPlayer
 }
 
+// Returns the next rules in `allRules`, going back to the first after the last
 func toggleRules(r rules) rules {
-	if r == ReversiRules {
-		return OthelloRules
-	}
-
-	return ReversiRules
+	return allRules[(slices.Index(allRules, r)+1)%len(allRules)]
 }
 
 func togglePlayerMode(pm playerMode) playerMode {
@@ -288,11 +797,45 @@ func togglePlayerMode(pm playerMode) playerMode {
 	return OnePlayer
 }
 
+type turnOutcome int
+
+const (
+	PlayerMoves turnOutcome = iota
+	PlayerPasses
+	GameOver
+)
+
+// Determines whether the given player (whose turn it now is) can move, must skip their turn or whether the game is over
+func getTurnOutcome(g grid, currentPlayer player, r rules, ds diskSupply) turnOutcome {
+	// A player who has run out of disks skips their turn, while their opponent uses up their own disks (Reversi only)
+	if ds.isExhausted(currentPlayer, r) {
+		opponent := toggleCurrentPlayer(currentPlayer)
+		if ds.isExhausted(opponent, r) || len(getAvailablePoints(g, opponent, r)) == 0 {
+			return GameOver
+		}
+		return PlayerPasses
+	}
+
+	// If no available moves for current player then it's game over (for Reversi) or skip turn (for Othello)
+	// If no available moves for either player then it's game over
+	// Otherwise continue game
+	if len(getAvailablePoints(g, currentPlayer, r)) > 0 {
+		return PlayerMoves
+	}
+
+	if r == ReversiRules || len(getAvailablePoints(g, toggleCurrentPlayer(currentPlayer), r)) == 0 {
+		return GameOver
+	}
+
+	return PlayerPasses
+}
+
+// Returns the points holding disks, not including blocked cells
 func getNonBlankPoints(g grid) []vector2d {
 	nonBlankPoints := make([]vector2d, 0)
 	for i, row := range g {
 		for j, cell := range row {
-			if cell != Blank {
+			if isDisk(cell) {
 				nonBlankPoints = append(nonBlankPoints, vector2d{j, i})
 			}
 		}
@@ -325,39 +868,64 @@ func getAvailablePoints(g grid, currentPlayer player, r rules) []vector2d {
 	}
 
 	// Get all neighbours of non-blank points in grid
-	neighbors := make(map[vector2d]bool)
+	// Using an array rather than a map as this is called very frequently by the engine
+	var neighbors [gridHeight][gridWidth]bool
 	for _, nonBlankPoint := range nonBlankPoints {
 		for i := -1; i <= 1; i++ {
 			for j := -1; j <= 1; j++ {
-				if i != 0 || j != 0 {
-					neighbor := vector2d{nonBlankPoint.x + j, nonBlankPoint.y + i}
-					neighbors[neighbor] = true
+				neighbor := vector2d{nonBlankPoint.x + j, nonBlankPoint.y + i}
+				if r.wraps() {
+					neighbor = wrapPoint(neighbor)
+				}
+				if (i != 0 || j != 0) && isPointInsideGrid(neighbor) {
+					neighbors[neighbor.y][neighbor.x] = true
 				}
 			}
 		}
 	}
 
-	// Keep only neighbours that are blank, inside the grid and will result in at least one flipped point
-	filteredNeighbors := make(map[vector2d]bool)
-	for neighbor := range neighbors {
-		if isPointInsideGrid(neighbor) && g[neighbor.y][neighbor.x] == Blank &&
-			len(getPointsToFlip(g, neighbor, currentPlayer)) > 0 {
-			filteredNeighbors[neighbor] = true
+	// Keep only neighbours that are blank and will result in at least one flipped point
+	filteredNeighbors := make([]vector2d, 0)
+	for i, row := range neighbors {
+		for j, isNeighbor := range row {
+			neighbor := vector2d{j, i}
+			if isNeighbor && g[neighbor.y][neighbor.x] == Blank && len(getPointsToFlip(g, neighbor, currentPlayer, r)) > 0 {
+				filteredNeighbors = append(filteredNeighbors, neighbor)
+			}
 		}
 	}
-
-	filteredNeighborsList := make([]vector2d, 0, len(filteredNeighbors))
-	for neighbor := range filteredNeighbors {
-		filteredNeighborsList = append(filteredNeighborsList, neighbor)
-	}
-	return filteredNeighborsList
+	return filteredNeighbors
 }
 
 func isPointInsideGrid(p vector2d) bool {
 	return p.x >= 0 && p.x < gridWidth && p.y >= 0 && p.y < gridHeight
 }
 
-func getPointsToFlip(g grid, selectedPoint vector2d, currentPlayer player) []vector2d {
+// Returns the given point moved onto the grid by wrapping it around the edges, as on a torus
+func wrapPoint(p vector2d) vector2d {
+	return vector2d{x: (p.x + gridWidth) % gridWidth, y: (p.y + gridHeight) % gridHeight}
+}
+
+// Moves the point one cell in the direction of the given key, wrapping around the edges of the grid, and returns
+// whether the key was one of the cursor keys
+func moveCursor(p *vector2d, msg tea.KeyMsg, km gameKeyMap) bool {
+	switch {
+	case key.Matches(msg, km.up):
+		p.y--
+	case key.Matches(msg, km.down):
+		p.y++
+	case key.Matches(msg, km.left):
+		p.x--
+	case key.Matches(msg, km.right):
+		p.x++
+	default:
+		return false
+	}
+	*p = wrapPoint(*p)
+	return true
+}
+
+func getPointsToFlip(g grid, selectedPoint vector2d, currentPlayer player, r rules) []vector2d {
 	// Maybe generate these automatically
 	directions := []vector2d{
 		{0, 1},
@@ -379,13 +947,19 @@ func getPointsToFlip(g grid, selectedPoint vector2d, currentPlayer player) []vec
 		pointsToFlip := make([]vector2d, 0)
 		for isInsideGrid && isNotBlank && !isCurrentPlayer {
 			currentPoint = vector2d{x: currentPoint.x + d.x, y: currentPoint.y + d.y}
+			if r.wraps() {
+				currentPoint = wrapPoint(currentPoint)
+			}
 
-			isInsideGrid = isPointInsideGrid(currentPoint)
+			// On a torus, a line that gets back to the selected point without reaching a disk of the current player's
+			// colour doesn't flip anything
+			isInsideGrid = isPointInsideGrid(currentPoint) && currentPoint != selectedPoint
 			if !isInsideGrid {
 				break
 			}
 
-			isNotBlank = g[currentPoint.y][currentPoint.x] != Blank
+			// Blocked cells end the line in the same way as blank cells
+			isNotBlank = isDisk(g[currentPoint.y][currentPoint.x])
 			isCurrentPlayer = g[currentPoint.y][currentPoint.x] == currentPlayer
 
 			if isInsideGrid && isNotBlank && !isCurrentPlayer {
@@ -396,7 +970,12 @@ func getPointsToFlip(g grid, selectedPoint vector2d, currentPlayer player) []vec
 		// If disk of current player's colour is reached, change all the intermediate disks to the current player's colour
 		// If blank cell or edge of grid is reached, don't change any disks
 		if isCurrentPlayer {
-			disksFlipped = append(disksFlipped, pointsToFlip...)
+			for _, p := range pointsToFlip {
+				// On a torus, lines in different directions can reach the same disk, which is only flipped once
+				if !slices.Contains(disksFlipped, p) {
+					disksFlipped = append(disksFlipped, p)
+				}
+			}
 		}
 	}
 
@@ -410,61 +989,11 @@ func flip(g *grid, points []vector2d, currentPlayer player) {
 	}
 }
 
-const accentColor1 = lipgloss.Color("63")
-const accentColor2 = lipgloss.Color("105")
-
-var darkPlayerStyle = lipgloss.NewStyle().
-	Foreground(lipgloss.Color("#ffffff")).
-	Background(lipgloss.Color("#000000"))
-
-var lightPlayerStyle = lipgloss.NewStyle().
-	Foreground(lipgloss.Color("#000000")).
-	Background(lipgloss.Color("#ffffff"))
-
-var selectedDarkPlayerStyle = lipgloss.NewStyle().
-	Underline(true).
-	Bold(true).
-	Foreground(accentColor2).
-	Background(lipgloss.Color("#000000"))
-
-var selectedLightPlayerStyle = lipgloss.NewStyle().
-	Foreground(lipgloss.Color("#000000")).
-	Background(accentColor2)
-
-var selectedBlankStyle = lipgloss.NewStyle().
-	Background(accentColor2)
-
-const highlightedColor = lipgloss.Color("#666666")
-
-var highlightedDarkPlayerStyle = lipgloss.NewStyle().
-	Foreground(highlightedColor).
-	Background(lipgloss.Color("#000000"))
-
-var highlightedLightPlayerStyle = lipgloss.NewStyle().
-	Foreground(lipgloss.Color("#000000")).
-	Background(highlightedColor)
-
-var availablePointStyle = lipgloss.NewStyle().
-	Background(lipgloss.Color("#404040"))
-
-var secondaryTextStyle = lipgloss.NewStyle().
-	Foreground(lipgloss.Color("241"))
-
-var accent1TextStyle = lipgloss.NewStyle().
-	Foreground(accentColor1).
-	Bold(true)
-
-var successTextStyle = lipgloss.NewStyle().
-	Foreground(lipgloss.Color("#00cc00"))
-
-var errorTextStyle = lipgloss.NewStyle().
-	Foreground(lipgloss.Color("#cc0000"))
-
 func computeScores(g grid) map[player]int {
 	m := make(map[player]int)
 	for _, row := range g {
 		for _, cell := range row {
-			if cell != Blank {
+			if isDisk(cell) {
 				m[cell]++
 			}
 		}
@@ -472,18 +1001,48 @@ func computeScores(g grid) map[player]int {
 	return m
 }
 
+const viewPaddingX = 6
+const viewPaddingY = 2
+
 func (m model) View() string {
 	scores := computeScores(m.grid)
 
+	// Show the text first, as it describes what has just happened, followed by a description of the board
+	if m.displayMode == ScreenReaderDisplay {
+		text := createTextView(m, scores, m.windowSize.x)
+		if m.view == TitleView || m.view == QuitConfirmation || m.view == StatsView {
+			return text
+		}
+		return lipgloss.JoinVertical(lipgloss.Left, text, "", createBoardDescription(m))
+	}
+
 	gridString := createGridView(m)
 
+	var historyPanel string
+	maxTextWidth := m.windowSize.x - lipgloss.Width(gridString) - 6
+	if isHistoryPanelVisible(m) {
+		historyPanel = createHistoryPanel(m)
+		maxTextWidth -= lipgloss.Width(historyPanel)
+	}
+
+	text := createTextView(m, scores, maxTextWidth)
+
+	return lipgloss.NewStyle().
+		Padding(viewPaddingY, viewPaddingX).
+		Render(lipgloss.JoinHorizontal(lipgloss.Top, gridString, historyPanel, text))
+}
+
+func createTextView(m model, scores map[player]int, maxTextWidth int) string {
+	if m.showHelp {
+		return createHelpView(m, maxTextWidth)
+	}
+
 	var text string
-	maxTextWidth := m.windowSize.x - ((gridWidth * 2) - 1) - 14
 	switch m.view {
 	case TitleView:
-		text = createTitleView(maxTextWidth, m.rules, m.playerMode)
+		text = createTitleView(m, maxTextWidth)
 	case QuitConfirmation:
-		text = createQuitConfirmationView(maxTextWidth)
+		text = createQuitConfirmationView(maxTextWidth, m.keys, m.getTheme())
 	case GameOverView:
 		text = createGameOverView(m, scores, maxTextWidth)
 	case PointSelection:
@@ -494,84 +1053,277 @@ func (m model) View() string {
 		text = createPassView(m, maxTextWidth)
 	case PointSelectionComputer:
 		text = createPointSelectionView(m, scores, maxTextWidth, true)
+	case AnalysisView:
+		text = createAnalysisView(m, maxTextWidth)
+	case ReplayView:
+		text = createReplayView(m, maxTextWidth)
+	case StatsView:
+		text = createStatsView(m, maxTextWidth)
+	case PuzzleView:
+		text = createPuzzleView(m, maxTextWidth)
+	case TutorialView:
+		text = createTutorialView(m, maxTextWidth)
+	case EditorView:
+		text = createEditorView(m, maxTextWidth)
 	}
-
-	return lipgloss.NewStyle().
-		Padding(2, 6).
-		Render(lipgloss.JoinHorizontal(lipgloss.Top, gridString, text))
+	return text
 }
 
 func createGridView(m model) string {
+	t := m.getTheme()
+
+	var evaluationShades map[vector2d]int
+	if m.showEvaluation && m.view == PointSelection {
+		evaluationShades = computeEvaluationShades(m.evaluations, len(t.evaluation))
+	}
+
+	g := getDisplayedGrid(m)
+	selectedPoint := m.selectedPoint
+	isSelectionVisible := m.view == PointSelection || (m.view == PointSelectionComputer && !m.computerThinking) ||
+		m.view == PuzzleView ||
+		(m.view == TutorialView && m.tutorial.isAwaitingMove()) || m.view == EditorView
+	availablePoints := m.availablePoints
+	disksFlipped := m.disksFlipped
+	isConfirmation := m.view == PointConfirmation
+	lastMove := getLastMove(m.record.moves)
+	var bestPoint *vector2d
+
+	// When analysing, show the grid after the move being analysed, along with the best move if a different move was
+	// played
+	if m.view == AnalysisView {
+		availablePoints = nil
+		lastMove = nil
+		if len(m.analysis) > 0 {
+			a := m.analysis[m.analysisIndex]
+			selectedPoint = a.move.point
+			isSelectionVisible = !a.move.pass
+			if !a.move.pass && a.after < a.before {
+				bestPoint = &a.best
+			}
+		}
+	}
+
+	// When replaying, show the disks flipped by the last move in the same way as the PointConfirmation view
+	if m.view == ReplayView {
+		availablePoints = nil
+		disksFlipped = m.replay.getDisksFlipped(m.record)
+		isConfirmation = len(disksFlipped) > 0
+		lastMove = getLastMove(m.record.moves[:m.replay.index])
+	}
+
+	// Once a puzzle has been attempted, show the grid after the move played, along with the best move if it was wrong
+	if m.view == PuzzleView && m.puzzles.attempt != nil {
+		availablePoints = nil
+		selectedPoint = *m.puzzles.attempt
+		if c := m.puzzles.check; c != nil && !c.isCorrect() {
+			bestPoint = &c.best
+		}
+	}
+
+	// Once the tutorial's move has been made, show the disks it flipped in the same way as the PointConfirmation view
+	if m.view == TutorialView && !m.tutorial.isAwaitingMove() {
+		availablePoints = nil
+		isConfirmation = m.tutorial.move != nil
+	}
+
+	// The position being edited isn't being played yet, so no moves are shown
+	if m.view == EditorView {
+		availablePoints = nil
+		lastMove = nil
+	}
+
+	// With markers, cells are marked using the spaces either side of them, so rows are padded with a space at each end
+	hasMarkers := m.displayMode == MarkersDisplay
+	blankGlyph := func(marker string) string {
+		if hasMarkers {
+			return marker
+		}
+		return " "
+	}
+
 	var gridStringBuilder strings.Builder
-	for i, row := range m.grid {
+	for i, row := range g {
+		cells := make([]string, len(row))
+		separators := make([]string, len(row)+1)
+		for j := range separators {
+			separators[j] = " "
+		}
+
 		for j, cell := range row {
 			point := vector2d{j, i}
-			if (m.view == PointSelection || m.view == PointSelectionComputer) && point == m.selectedPoint {
+			// Disks waiting to be flipped by the animation are shown as they were before the move
+			if m.view == PointConfirmation {
+				switch phase := m.flipAnimation.getPhase(point); {
+				case phase == 0:
+					cell = toggleCurrentPlayer(cell)
+				case phase < framesPerDisk:
+					cells[j] = t.board.Render(flipGlyphs[phase-1])
+					continue
+				}
+			}
+
+			if hasMarkers && isSelectionVisible && point == selectedPoint {
+				separators[j], separators[j+1] = "[", "]"
+			} else if hasMarkers && isConfirmation && cell != Blank && slices.Contains(disksFlipped, point) {
+				separators[j+1] = "*"
+			}
+
+			if cell == Blocked {
+				if isSelectionVisible && point == selectedPoint {
+					cells[j] = t.selectedBlank.Render(blockedGlyph)
+				} else {
+					cells[j] = t.blocked.Render(blockedGlyph)
+				}
+				continue
+			}
+
+			if isSelectionVisible && point == selectedPoint {
 				switch cell {
 				case DarkPlayer:
-					gridStringBuilder.WriteString(selectedDarkPlayerStyle.Render("X"))
+					cells[j] = t.selectedDarkPlayer.Render("X")
 				case LightPlayer:
-					gridStringBuilder.WriteString(selectedLightPlayerStyle.Render("O"))
+					cells[j] = t.selectedLightPlayer.Render("O")
 				default:
-					gridStringBuilder.WriteString(selectedBlankStyle.Render(" "))
+					cells[j] = t.selectedBlank.Render(blankGlyph(" "))
+				}
+			} else if lastMove != nil && point == *lastMove && cell != Blank {
+				switch cell {
+				case DarkPlayer:
+					cells[j] = t.lastMoveDarkPlayer.Render("X")
+				case LightPlayer:
+					cells[j] = t.lastMoveLightPlayer.Render("O")
 				}
-			} else if (m.view == PointConfirmation && m.grid[point.y][point.x] != Blank && !slices.Contains(m.disksFlipped, point)) ||
-				(m.view == PointSelectionComputer && m.grid[point.y][point.x] != Blank && point != m.selectedPoint) {
+			} else if (isConfirmation && cell != Blank && !slices.Contains(disksFlipped, point)) ||
+				(m.view == PointSelectionComputer && !m.computerThinking && cell != Blank && point != m.selectedPoint) {
 				switch cell {
 				case DarkPlayer:
-					gridStringBuilder.WriteString(highlightedDarkPlayerStyle.Render("X"))
+					cells[j] = t.highlightedDarkPlayer.Render("X")
 				case LightPlayer:
-					gridStringBuilder.WriteString(highlightedLightPlayerStyle.Render("O"))
+					cells[j] = t.highlightedLightPlayer.Render("O")
 				}
 			} else {
 				switch cell {
 				case DarkPlayer:
-					gridStringBuilder.WriteString(darkPlayerStyle.Render("X"))
+					cells[j] = t.darkPlayer.Render("X")
 				case LightPlayer:
-					gridStringBuilder.WriteString(lightPlayerStyle.Render("O"))
+					cells[j] = t.lightPlayer.Render("O")
 				default:
-					if slices.Contains(m.availablePoints, point) {
-						gridStringBuilder.WriteString(availablePointStyle.Render(" "))
+					if shade, ok := evaluationShades[point]; ok {
+						// Shades are numbered from 1 so they can't be mistaken for a blank cell
+						cells[j] = t.evaluation[shade].Render(blankGlyph(strconv.Itoa(shade + 1)))
+					} else if bestPoint != nil && point == *bestPoint {
+						cells[j] = t.bestMove.Render(blankGlyph("!"))
+					} else if slices.Contains(availablePoints, point) {
+						cells[j] = t.availablePoint.Render(blankGlyph("·"))
 					} else {
-						gridStringBuilder.WriteString(" ")
+						cells[j] = t.board.Render(" ")
 					}
 				}
 			}
+		}
 
-			if j < len(row)-1 {
-				gridStringBuilder.WriteString(" ")
+		if hasMarkers {
+			gridStringBuilder.WriteString(t.board.Render(separators[0]))
+		}
+		for j, cell := range cells {
+			gridStringBuilder.WriteString(cell)
+			if hasMarkers || j < len(cells)-1 {
+				gridStringBuilder.WriteString(t.board.Render(separators[j+1]))
 			}
 		}
 
-		if i < len(m.grid)-1 {
+		if i < len(g)-1 {
 			gridStringBuilder.WriteString("\n")
 		}
 	}
 
-	return lipgloss.NewStyle().
+	gridView := lipgloss.NewStyle().
 		BorderStyle(lipgloss.RoundedBorder()).
-		BorderForeground(accentColor1).
-		MarginRight(6).
+		BorderForeground(t.borderColor).
 		Render(gridStringBuilder.String())
+
+	if m.showCoordinates {
+		gridView = addCoordinateLabels(gridView, getGridPadding(m), t)
+	}
+
+	return lipgloss.NewStyle().
+		MarginRight(6).
+		Render(gridView)
+}
+
+// Adds column letters above, and row numbers to the left of, the bordered grid
+func addCoordinateLabels(gridView string, padding int, t theme) string {
+	rowLabelWidth := len(getRowLabel(gridHeight - 1))
+
+	columnLabels := make([]string, 0, gridWidth)
+	for j := 0; j < gridWidth; j++ {
+		columnLabels = append(columnLabels, getColumnLabel(j))
+	}
+	// Offset by the row labels, the space after them, the left border and any padding inside it
+	columnLabelsString := strings.Repeat(" ", rowLabelWidth+2+padding) + strings.Join(columnLabels, " ")
+
+	// Leave a blank line for the top border
+	rowLabels := make([]string, 0, gridHeight+1)
+	rowLabels = append(rowLabels, "")
+	for i := 0; i < gridHeight; i++ {
+		rowLabels = append(rowLabels, getRowLabel(i))
+	}
+	rowLabelsString := lipgloss.NewStyle().
+		Width(rowLabelWidth).
+		MarginRight(1).
+		Align(lipgloss.Right).
+		Render(lipgloss.JoinVertical(lipgloss.Right, rowLabels...))
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		t.secondaryText.Render(columnLabelsString),
+		lipgloss.JoinHorizontal(lipgloss.Top, t.secondaryText.Render(rowLabelsString), gridView))
 }
 
-func createTitleView(maxWidth int, r rules, pm playerMode) string {
+func createTitleView(m model, maxWidth int) string {
+	s := m.settings
+	t := s.getTheme()
 	title := fmt.Sprintf(` ____                         _ 
 |  _ \ _____   _____ _ __ ___(_)
 | |_) / _ \ \ / / _ \ '__/ __| |
 |  _ <  __/\ V /  __/ |  \__ \ |
 |_| \_\___| \_/ \___|_|  |___/_|  %s`,
-		secondaryTextStyle.Render(version))
+		t.secondaryText.Render(version))
+	// Screen readers would read out the title's characters one by one
+	if s.displayMode == ScreenReaderDisplay {
+		title = fmt.Sprintf("Reversi %s", version)
+	}
 
-	textStrings := []string{
+	titleRadioButtons := getTitleRadioButtons()
+	textStrings := make([]string, 0, len(titleRadioButtons)+6)
+	textStrings = append(textStrings, "")
+	for _, rb := range titleRadioButtons {
+		textStrings = append(textStrings, rb.view(s))
+	}
+	textStrings = append(textStrings,
 		"",
-		createRadioButton([]playerMode{OnePlayer, TwoPlayer}, pm, "Player mode", "P"),
-		createRadioButton([]rules{OthelloRules, ReversiRules}, r, "Rules", "R"),
+		fmt.Sprintf("%s: %s", s.rules, s.rules.getDescription()),
+	)
+	if startText := createStartText(m.record); startText != "" {
+		textStrings = append(textStrings, startText)
+	}
+	textStrings = append(textStrings,
+		fmt.Sprintf("Profile: %s; rating: %.0f; suggested difficulty: %s %s", s.profileName,
+			m.profile.Rating.getValue(initialRating),
+			suggestDifficulty(m.profile.Rating.getValue(initialRating), m.computerRatings),
+			t.secondaryText.Render(fmt.Sprintf("(press %s for statistics)", strings.ToUpper(formatFirstKeys(s.keys.stats))))),
+	)
+	textStrings = append(textStrings,
 		"",
 		"Press any other key to start...",
-		"",
-		secondaryTextStyle.Render("p: toggle player mode • r: toggle rules • any other key: continue"),
+	)
+	if m.settingsSaveErr != nil {
+		textStrings = append(textStrings, "", t.errorText.Render(fmt.Sprintf("Could not save settings: %v", m.settingsSaveErr)))
+	}
+	if m.profileErr != nil {
+		textStrings = append(textStrings, "", t.errorText.Render(fmt.Sprintf("Could not load profile: %v", m.profileErr)))
 	}
+	textStrings = append(textStrings, "",
+		t.secondaryText.Render(createTitleHelp(s.keys)))
 	text := lipgloss.NewStyle().
 		Width(maxWidth).
 		Render(lipgloss.JoinVertical(lipgloss.Left, textStrings...))
@@ -579,13 +1331,13 @@ func createTitleView(maxWidth int, r rules, pm playerMode) string {
 	return lipgloss.JoinVertical(lipgloss.Left, title, text)
 }
 
-func createQuitConfirmationView(maxWidth int) string {
+func createQuitConfirmationView(maxWidth int, km keyMap, t theme) string {
 	textStrings := []string{
 		"Are you sure you want to quit?",
 		"",
 		"Any game progress will be lost.",
 		"",
-		secondaryTextStyle.Render("enter: quit • any other key: cancel"),
+		t.secondaryText.Render(createQuitConfirmationHelp(km)),
 	}
 
 	return lipgloss.NewStyle().
@@ -594,35 +1346,70 @@ func createQuitConfirmationView(maxWidth int) string {
 }
 
 func createGameOverView(m model, scores map[player]int, maxWidth int) string {
+	t := m.getTheme()
 	var resultString string
-	if scores[LightPlayer] == scores[DarkPlayer] {
+	if m.clock.isFlagged() {
+		resultString = fmt.Sprintf("%s won on time!", toggleCurrentPlayer(m.clock.flagged))
+	} else if winner, ok := getLeader(scores, m.rules); ok {
+		resultString = fmt.Sprintf("%s won!", winner)
+	} else {
 		resultString = "Tie!"
-	} else if scores[DarkPlayer] > scores[LightPlayer] {
-		resultString = fmt.Sprintf("%s won!", DarkPlayer)
-	} else if scores[LightPlayer] > scores[DarkPlayer] {
-		resultString = fmt.Sprintf("%s won!", LightPlayer)
 	}
 
 	scoreString := fmt.Sprintf("%s: %d; %s: %d", DarkPlayer.String(), scores[DarkPlayer], LightPlayer.String(),
 		scores[LightPlayer])
 
+	hints := m.record.countHints()
+
 	var infoString string
-	if m.rules == ReversiRules {
+	if m.clock.isFlagged() {
+		infoString = fmt.Sprintf("%s ran out of time.", m.clock.flagged)
+	} else if opponent := toggleCurrentPlayer(m.currentPlayer); m.supply.isExhausted(m.currentPlayer, m.rules) {
+		// The game only ends on running out of disks when the opponent can't carry on either (Reversi only)
+		if m.supply.isExhausted(opponent, m.rules) {
+			infoString = "Both players have run out of disks."
+		} else {
+			infoString = fmt.Sprintf("%s has run out of disks and there are no available moves for %s.", m.currentPlayer,
+				opponent)
+		}
+	} else if m.rules == ReversiRules {
 		infoString = fmt.Sprintf("No available moves for %s.", m.currentPlayer)
 	} else {
 		infoString = "No available moves for either player."
 	}
 
 	textStrings := []string{
-		accent1TextStyle.Render("Game over!"),
+		t.accent1Text.Render("Game over!"),
 		"",
 		infoString,
 		"",
 		resultString,
 		scoreString,
-		"",
-		secondaryTextStyle.Render("enter: play again • any other key: quit"),
 	}
+	if startText := createStartText(m.record); startText != "" {
+		textStrings = append(textStrings, startText)
+	}
+	if len(hints) > 0 {
+		textStrings = append(textStrings, fmt.Sprintf("Hints used: %s: %d; %s: %d", DarkPlayer.String(),
+			hints[DarkPlayer], LightPlayer.String(), hints[LightPlayer]))
+	}
+	if m.profileErr != nil {
+		textStrings = append(textStrings, "", t.errorText.Render(fmt.Sprintf("Could not update statistics: %v", m.profileErr)))
+	} else if m.gameRecorded {
+		totals := m.profile.Stats.getTotals()
+		textStrings = append(textStrings, "", fmt.Sprintf("Result recorded for %s (%s, %s, %s in total)", m.profileName,
+			english.Plural(totals.Wins, "win", ""), english.Plural(totals.Losses, "loss", "losses"),
+			english.Plural(totals.Draws, "draw", "")),
+			createRatingChangeText(m.profile.Rating))
+	} else if m.playerMode == OnePlayer && !m.record.hasUsualStart() {
+		textStrings = append(textStrings, "", t.secondaryText.Render("Games from custom positions aren't recorded in statistics"))
+	}
+	if m.lastSave.err != nil {
+		textStrings = append(textStrings, "", t.errorText.Render(fmt.Sprintf("Could not save game: %v", m.lastSave.err)))
+	} else if m.lastSave.path != "" {
+		textStrings = append(textStrings, "", t.successText.Render(fmt.Sprintf("Game saved to %s", m.lastSave.path)))
+	}
+	textStrings = append(textStrings, "", t.secondaryText.Render(createGameOverHelp(m.keys)))
 
 	return lipgloss.NewStyle().
 		Width(maxWidth).
@@ -630,24 +1417,59 @@ func createGameOverView(m model, scores map[player]int, maxWidth int) string {
 }
 
 func createPointSelectionView(m model, scores map[player]int, maxWidth int, isComputerTurn bool) string {
+	t := m.getTheme()
 	textStrings := make([]string, 0, 7)
 
-	textStrings = append(textStrings, createTurnText(m.currentPlayer))
-	textStrings = append(textStrings, createGameStatusText(scores))
+	textStrings = append(textStrings, createTurnText(m.currentPlayer, t))
+	if m.clock.isEnabled() {
+		textStrings = append(textStrings, createClockText(m))
+	}
+	textStrings = append(textStrings, createGameStatusText(scores, m.rules, m.supply))
+	if startText := createStartText(m.record); startText != "" {
+		textStrings = append(textStrings, t.secondaryText.Render(startText))
+	}
 	textStrings = append(textStrings, "")
 
-	if isComputerTurn {
-		textStrings = append(textStrings, "Computer places disk here")
-		textStrings = append(textStrings, "", secondaryTextStyle.Render("any key: continue"))
+	if isComputerTurn && m.computerThinking {
+		textStrings = append(textStrings, "Computer is thinking...")
+	} else if isComputerTurn {
+		textStrings = append(textStrings, fmt.Sprintf("Computer places disk at %s", m.selectedPoint))
+		textStrings = append(textStrings, "", t.secondaryText.Render("any key: continue"))
 	} else {
 		textStrings = append(textStrings, "Choose where to place your disk")
 
 		if slices.Contains(m.availablePoints, m.selectedPoint) {
-			textStrings = append(textStrings, successTextStyle.Render("Can place disk here"))
-			textStrings = append(textStrings, "", secondaryTextStyle.Render("arrow keys: move • enter: place tile • q: exit"))
+			textStrings = append(textStrings, t.successText.Render(fmt.Sprintf("Can place disk at %s", m.selectedPoint)))
 		} else {
-			textStrings = append(textStrings, errorTextStyle.Render("Cannot place disk here"))
-			textStrings = append(textStrings, "", secondaryTextStyle.Render("arrow keys: move • q: exit"))
+			textStrings = append(textStrings, t.errorText.Render(fmt.Sprintf("Cannot place disk at %s", m.selectedPoint)))
+		}
+
+		if m.hintPending {
+			textStrings = append(textStrings, t.secondaryText.Render("Finding a hint..."))
+		} else if m.hintUsed && m.selectedPoint == m.hintPoint {
+			textStrings = append(textStrings, t.secondaryText.Render("Hint: the computer would place its disk here"))
+		}
+		if m.showEvaluation && m.evaluations == nil {
+			textStrings = append(textStrings, t.secondaryText.Render("Evaluating moves..."))
+		}
+
+		if m.coordinateInput.active {
+			textStrings = append(textStrings, "", fmt.Sprintf("Go to: %s_", m.coordinateInput.text))
+			if m.coordinateInput.err != nil {
+				textStrings = append(textStrings, t.errorText.Render(fmt.Sprintf("Invalid coordinate; expected a column %s–%s and a row %s–%s, e.g. f5",
+					getColumnLabel(0), getColumnLabel(gridWidth-1), getRowLabel(0), getRowLabel(gridHeight-1))))
+			}
+
+			confirmText := "move cursor"
+			if m.typedMoveAction == PlaceDiskAtTypedPoint {
+				confirmText = "place disk"
+			}
+			textStrings = append(textStrings, "", t.secondaryText.Render(joinHelpEntries(
+				formatHelpEntry(formatFirstKeys(m.keys.coordinateInput.confirm), confirmText),
+				formatShortHelp(m.keys.coordinateInput.cancel))))
+		} else {
+			textStrings = append(textStrings, "",
+				t.secondaryText.Render(createGameHelp(m.keys, slices.Contains(m.availablePoints, m.selectedPoint))))
 		}
 	}
 
@@ -657,17 +1479,21 @@ func createPointSelectionView(m model, scores map[player]int, maxWidth int, isCo
 }
 
 func createPointConfirmationView(m model, scores map[player]int, maxWidth int) string {
+	t := m.getTheme()
 	textStrings := make([]string, 0, 6)
 
-	textStrings = append(textStrings, createTurnText(m.currentPlayer))
-	textStrings = append(textStrings, createGameStatusText(scores))
+	textStrings = append(textStrings, createTurnText(m.currentPlayer, t))
+	if m.clock.isEnabled() {
+		textStrings = append(textStrings, createClockText(m))
+	}
+	textStrings = append(textStrings, createGameStatusText(scores, m.rules, m.supply))
 
 	if len(m.disksFlipped) == 0 {
 		textStrings = append(textStrings, "", "No disks flipped this time")
 	} else {
 		textStrings = append(textStrings, "", fmt.Sprintf("%s flipped %s!", m.currentPlayer, english.Plural(len(m.disksFlipped), "disk", "")))
 	}
-	textStrings = append(textStrings, "", secondaryTextStyle.Render("any key: continue"))
+	textStrings = append(textStrings, "", t.secondaryText.Render("any key: continue"))
 
 	return lipgloss.NewStyle().
 		Width(maxWidth).
@@ -679,15 +1505,79 @@ type radioButtonItem interface {
 	String() string
 }
 
-func createRadioButton[T radioButtonItem](options []T, selected T, label string, key string) string {
+// A setting shown on the title screen as a radio button, which can be selected by clicking on one of its options
+type titleRadioButton struct {
+	// Name of the setting in the config file
+	name string
+	view func(s settings) string
+	// Selects the option at the given horizontal offset within the radio button, returning whether there was one
+	click func(s *settings, x int) bool
+}
+
+func newTitleRadioButton[T radioButtonItem](options []T, label string, name string,
+	binding func(km titleKeyMap) key.Binding, get func(s *settings) *T) titleRadioButton {
+	return titleRadioButton{
+		name: name,
+		view: func(s settings) string {
+			return createRadioButton(options, *get(&s), label, formatFirstKeys(binding(s.keys.title)), s.getTheme())
+		},
+		click: func(s *settings, x int) bool {
+			i := getRadioButtonOptionIndex(options, label, x)
+			if i < 0 {
+				return false
+			}
+
+			*get(s) = options[i]
+			return true
+		},
+	}
+}
+
+func getTitleRadioButtons() []titleRadioButton {
+	return []titleRadioButton{
+		newTitleRadioButton([]playerMode{OnePlayer, TwoPlayer}, "Player mode", "mode",
+			func(km titleKeyMap) key.Binding { return km.playerMode },
+			func(s *settings) *playerMode { return &s.playerMode }),
+		newTitleRadioButton(difficulties, "Difficulty", "difficulty",
+			func(km titleKeyMap) key.Binding { return km.difficulty },
+			func(s *settings) *difficulty { return &s.difficulty }),
+		newTitleRadioButton(allRules, "Rules", "rules",
+			func(km titleKeyMap) key.Binding { return km.rules },
+			func(s *settings) *rules { return &s.rules }),
+		newTitleRadioButton(allBoardShapes, "Board", "board",
+			func(km titleKeyMap) key.Binding { return km.board },
+			func(s *settings) *boardShape { return &s.board }),
+		newTitleRadioButton(allOpeningModes, "Opening", "opening",
+			func(km titleKeyMap) key.Binding { return km.opening },
+			func(s *settings) *openingMode { return &s.opening }),
+		newTitleRadioButton(timeControls, "Time control", "time-control",
+			func(km titleKeyMap) key.Binding { return km.timeControl },
+			func(s *settings) *timeControl { return &s.timeControl }),
+		newTitleRadioButton([]typedMoveAction{MoveCursorToTypedPoint, PlaceDiskAtTypedPoint}, "Typed moves", "typed-moves",
+			func(km titleKeyMap) key.Binding { return km.typedMoves },
+			func(s *settings) *typedMoveAction { return &s.typedMoveAction }),
+		newTitleRadioButton(getThemeNames(), "Theme", "theme",
+			func(km titleKeyMap) key.Binding { return km.theme },
+			func(s *settings) *themeName { return &s.theme }),
+		newTitleRadioButton(allDisplayModes, "Display", "display",
+			func(km titleKeyMap) key.Binding { return km.display },
+			func(s *settings) *displayMode { return &s.displayMode }),
+		newTitleRadioButton(allAnimationSpeeds, "Flip animation", "animation",
+			func(km titleKeyMap) key.Binding { return km.animation },
+			func(s *settings) *animationSpeed { return &s.animationSpeed }),
+		newTitleRadioButton(allAdvanceModes, "Continue after moves", "continue",
+			func(km titleKeyMap) key.Binding { return km.advanceMode },
+			func(s *settings) *advanceMode { return &s.advanceMode }),
+	}
+}
+
+func createRadioButton[T radioButtonItem](options []T, selected T, label string, key string, t theme) string {
 	var builder strings.Builder
 	builder.WriteString(label)
 	builder.WriteString(": ")
 	for i, option := range options {
 		if option == selected {
-			builder.WriteString(lipgloss.NewStyle().
-				Foreground(accentColor2).
-				Render(option.String() + " [▪]"))
+			builder.WriteString(t.accent2Text.Render(option.String() + " [▪]"))
 		} else {
 			builder.WriteString(option.String() + " [ ]")
 		}
@@ -698,49 +1588,93 @@ func createRadioButton[T radioButtonItem](options []T, selected T, label string,
 
 		builder.WriteString(" ")
 	}
-	builder.WriteString(secondaryTextStyle.Render(fmt.Sprintf("(press %s)", strings.ToUpper(key))))
+	builder.WriteString(t.secondaryText.Render(fmt.Sprintf("(press %s)", strings.ToUpper(key))))
 
 	return builder.String()
 }
 
 func createPassView(m model, maxWidth int) string {
+	t := m.getTheme()
 	textStrings := make([]string, 0, 6)
-	textStrings = []string{
-		createTurnText(m.currentPlayer),
-		fmt.Sprintf("No available moves for %s; skipping turn...", m.currentPlayer),
-		"",
-		secondaryTextStyle.Render("any key: continue"),
+	textStrings = append(textStrings, createTurnText(m.currentPlayer, t))
+	if m.clock.isEnabled() {
+		textStrings = append(textStrings, createClockText(m))
 	}
+	passString := fmt.Sprintf("No available moves for %s; skipping turn...", m.currentPlayer)
+	if m.supply.isExhausted(m.currentPlayer, m.rules) {
+		passString = fmt.Sprintf("%s has run out of disks; skipping turn...", m.currentPlayer)
+	}
+	textStrings = append(textStrings,
+		passString,
+		"",
+		t.secondaryText.Render("any key: continue"),
+	)
 
 	return lipgloss.NewStyle().
 		Width(maxWidth).
 		Render(lipgloss.JoinVertical(lipgloss.Left, textStrings...))
 }
 
-func createTurnText(currentPlayer player) string {
-	return accent1TextStyle.Render(fmt.Sprintf("%s (%s)'s turn", currentPlayer.String(), currentPlayer.toSymbol()))
+func createTurnText(currentPlayer player, t theme) string {
+	return t.accent1Text.Render(fmt.Sprintf("%s (%s)'s turn", currentPlayer.String(), currentPlayer.toSymbol()))
 }
 
-func createGameStatusText(scores map[player]int) string {
+// Returns the player who is winning according to the scores, or false if it's a tie
+// Under Anti-Reversi rules, the player with the fewest disks is winning
+func getLeader(scores map[player]int, r rules) (player, bool) {
+	differential := scores[DarkPlayer] - scores[LightPlayer]
+	if r.isMisere() {
+		differential = -differential
+	}
+
+	switch {
+	case differential > 0:
+		return DarkPlayer, true
+	case differential < 0:
+		return LightPlayer, true
+	default:
+		return 0, false
+	}
+}
+
+// Describes who is winning and the scores, along with the disks each player has left if the rules limit them
+func createGameStatusText(scores map[player]int, r rules, ds diskSupply) string {
 	var scoreStringBuilder strings.Builder
-	if scores[LightPlayer] == scores[DarkPlayer] {
+	if leader, ok := getLeader(scores, r); ok {
+		scoreStringBuilder.WriteString(fmt.Sprintf("%s winning!", leader))
+	} else {
 		scoreStringBuilder.WriteString("Tie")
-	} else if scores[DarkPlayer] > scores[LightPlayer] {
-		scoreStringBuilder.WriteString(fmt.Sprintf("%s winning!", DarkPlayer))
-	} else if scores[LightPlayer] > scores[DarkPlayer] {
-		scoreStringBuilder.WriteString(fmt.Sprintf("%s winning!", LightPlayer))
 	}
 	scoreStringBuilder.WriteString("\n")
 	scoreStringBuilder.WriteString(fmt.Sprintf("%s: %d; %s: %d", DarkPlayer.String(), scores[DarkPlayer], LightPlayer.String(),
 		scores[LightPlayer]))
+	if r.hasDiskSupply() {
+		scoreStringBuilder.WriteString("\n")
+		scoreStringBuilder.WriteString(createDiskSupplyText(ds))
+	}
 
 	return scoreStringBuilder.String()
 }
 
 func main() {
-	p := tea.NewProgram(initialModel())
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error: %v", err)
-		os.Exit(1)
+	if err := loadUserThemes(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not load themes: %v\n", err)
+	}
+
+	s := defaultSettings
+	if err := loadSettings(&s); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not load settings: %v\n", err)
+	}
+
+	if err := run(os.Args[1:], s, os.Stdout); err != nil {
+		switch {
+		case errors.Is(err, flag.ErrHelp):
+			return
+		case errors.Is(err, errUsage):
+			os.Exit(2)
+		default:
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 	}
 }
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"golang.org/x/exp/slices"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Formats the points in order, so that they can be compared regardless of the order they were found in
+func formatSortedPoints(points []vector2d) string {
+	pointStrings := formatPointList(points)
+	slices.Sort(pointStrings)
+	return strings.Join(pointStrings, " ")
+}
+
+func TestGetPointsToFlip(t *testing.T) {
+	tests := []struct {
+		name     string
+		position string
+		rules    rules
+		point    string
+		want     string
+	}{
+		{
+			name:     "opening move",
+			position: "...........................OX......XO...........................",
+			rules:    OthelloRules,
+			point:    "d3",
+			want:     "d4",
+		},
+		{
+			name: "blocked cell ends line",
+			position: `
+				.O#X....
+				........
+				........
+				........
+				........
+				........
+				........
+				........`,
+			rules: OthelloRules,
+			point: "a1",
+			want:  "",
+		},
+		{
+			name: "lines stop at edges",
+			position: `
+				OX......
+				........
+				........
+				........
+				........
+				........
+				........
+				........`,
+			rules: OthelloRules,
+			point: "h1",
+			want:  "",
+		},
+		{
+			name: "lines wrap around edges on a torus",
+			position: `
+				OX......
+				........
+				........
+				........
+				........
+				........
+				........
+				........`,
+			rules: TorusRules,
+			point: "h1",
+			want:  "a1",
+		},
+		{
+			name: "blocked cell ends line on a torus",
+			position: `
+				#X......
+				........
+				........
+				........
+				........
+				........
+				........
+				........`,
+			rules: TorusRules,
+			point: "h1",
+			want:  "",
+		},
+		{
+			name: "overlapping lines flip each disk once on a torus",
+			position: `
+				........
+				.O......
+				..O.....
+				...O.X..
+				....O...
+				...O.X..
+				..O.....
+				.O......`,
+			rules: TorusRules,
+			point: "a1",
+			want:  "b2 b8 c3 c7 d4 d6 e5",
+		},
+		{
+			name: "line back to the same cell doesn't flip itself on a torus",
+			position: `
+				........
+				........
+				........
+				OO.OOOOO
+				........
+				........
+				........
+				........`,
+			rules: TorusRules,
+			point: "c4",
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := parseTestPosition(t, tt.position, tt.rules)
+			p, err := parsePoint(tt.point)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := getPointsToFlip(s.grid, p, DarkPlayer, tt.rules)
+			if formatSortedPoints(got) != tt.want {
+				t.Errorf("got %q, want %q", formatSortedPoints(got), tt.want)
+			}
+			if len(got) != len(strings.Fields(tt.want)) {
+				t.Errorf("got %d points, want %d", len(got), len(strings.Fields(tt.want)))
+			}
+		})
+	}
+}
+
+func TestGetAvailablePoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		position string
+		rules    rules
+		want     string
+	}{
+		{
+			name:     "opening moves",
+			position: "...........................OX......XO...........................",
+			rules:    OthelloRules,
+			want:     "c4 d3 e6 f5",
+		},
+		{
+			name:     "first disks in the centre under Reversi rules",
+			position: "................................................................",
+			rules:    ReversiRules,
+			want:     "d4 d5 e4 e5",
+		},
+		{
+			name: "no moves across edges",
+			position: `
+				X......O
+				........
+				........
+				........
+				........
+				........
+				........
+				........`,
+			rules: OthelloRules,
+			want:  "",
+		},
+		{
+			name: "moves across edges on a torus",
+			position: `
+				X......O
+				........
+				........
+				........
+				........
+				........
+				........
+				........`,
+			rules: TorusRules,
+			want:  "g1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := parseTestPosition(t, tt.position, tt.rules)
+			if got := formatSortedPoints(getAvailablePoints(s.grid, DarkPlayer, tt.rules)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeEvaluationShades(t *testing.T) {
+	a1, b1, c1 := vector2d{0, 0}, vector2d{1, 0}, vector2d{2, 0}
+	tests := []struct {
+		name        string
+		evaluations map[vector2d]int
+		want        map[vector2d]int
+	}{
+		{name: "spread", evaluations: map[vector2d]int{a1: -10, b1: 0, c1: 10}, want: map[vector2d]int{a1: 0, b1: 1, c1: 3}},
+		{name: "all equal", evaluations: map[vector2d]int{a1: 4, b1: 4}, want: map[vector2d]int{a1: 3, b1: 3}},
+		{name: "not evaluated yet", want: map[vector2d]int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := computeEvaluationShades(tt.evaluations, 4); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Number of lines in the title above the title screen's radio buttons, including the blank line after it
+const titleHeight = 6
+
+// Returns the screen position of the top-left cell of the grid, allowing for the padding around the view, the
+// coordinate labels, the grid's border and the padding inside it
+func getGridOrigin(m model) (int, int) {
+	x, y := viewPaddingX+1+getGridPadding(m), viewPaddingY+1
+	if m.showCoordinates {
+		x += len(getRowLabel(gridHeight-1)) + 1
+		y++
+	}
+	return x, y
+}
+
+// Returns the grid cell at the given screen position, if any
+// Cells are separated by spaces, so clicks on a space are ignored
+func getPointAt(m model, x int, y int) (vector2d, bool) {
+	originX, originY := getGridOrigin(m)
+	if x < originX || (x-originX)%2 != 0 {
+		return vector2d{}, false
+	}
+
+	p := vector2d{(x - originX) / 2, y - originY}
+	return p, isPointInsideGrid(p)
+}
+
+// Returns the index of the option at the given horizontal offset within a radio button created by
+// `createRadioButton`, or -1 if there isn't one
+func getRadioButtonOptionIndex[T radioButtonItem](options []T, label string, x int) int {
+	start := lipgloss.Width(label + ": ")
+	for i, option := range options {
+		end := start + lipgloss.Width(option.String()+" [ ]")
+		if x >= start && x < end {
+			return i
+		}
+
+		// Skip the separator
+		start = end + 1
+		if i != len(options)-1 {
+			start++
+		}
+	}
+
+	return -1
+}
+
+// Selects the title screen option at the given screen position, if any, returning the name of the setting that changed
+// or an empty string if none did
+func clickTitleRadioButton(m model, x int, y int) (model, string) {
+	gridString := createGridView(m)
+	maxTextWidth := m.windowSize.x - lipgloss.Width(gridString) - 6
+
+	textX := x - viewPaddingX - lipgloss.Width(gridString)
+	row := y - viewPaddingY - titleHeight
+	titleRadioButtons := getTitleRadioButtons()
+	if row < 0 || row >= len(titleRadioButtons) {
+		return m, ""
+	}
+
+	// Positions can't be worked out for radio buttons that have been wrapped, which would also move those below
+	for _, rb := range titleRadioButtons[:row+1] {
+		if lipgloss.Width(rb.view(m.settings)) > maxTextWidth {
+			return m, ""
+		}
+	}
+
+	if !titleRadioButtons[row].click(&m.settings, textX) {
+		return m, ""
+	}
+
+	// Reset in case the rules or time control have changed
+	return resetModel(m), titleRadioButtons[row].name
+}
+
+// Selects the grid cell at the given screen position, or acts on it if it's already selected, so that clicking on a
+// cell a second time (including by double-clicking) acts on it
+func clickPoint(m *model, x int, y int, act func(m *model) tea.Cmd) tea.Cmd {
+	p, ok := getPointAt(*m, x, y)
+	if !ok {
+		return nil
+	}
+
+	if p == m.selectedPoint {
+		return act(m)
+	}
+	m.selectedPoint = p
+	return nil
+}
+
+func updateMouse(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	// Nothing is drawn in the places that would be clicked on
+	if m.displayMode == ScreenReaderDisplay {
+		return m, nil
+	}
+
+	switch msg.Type {
+	case tea.MouseLeft:
+		switch m.view {
+		case TitleView:
+			var setting string
+			if m, setting = clickTitleRadioButton(m, msg.X, msg.Y); setting != "" {
+				return m, saveSettingCmd(m.settings, setting)
+			}
+		case PointSelection:
+			if m.coordinateInput.active {
+				break
+			}
+			return m, clickPoint(&m, msg.X, msg.Y, takeTurn)
+		case PointSelectionComputer:
+			if m.computerThinking {
+				break
+			}
+			return m, takeTurn(&m)
+		case TutorialView:
+			if !m.tutorial.isAwaitingMove() {
+				advanceTutorial(&m)
+				break
+			}
+
+			previous := m.selectedPoint
+			clickPoint(&m, msg.X, msg.Y, func(m *model) tea.Cmd {
+				makeTutorialMove(m)
+				return nil
+			})
+			if m.selectedPoint != previous {
+				m.tutorial.wrongMove = false
+			}
+		case PuzzleView:
+			return m, clickPoint(&m, msg.X, msg.Y, attemptPuzzle)
+		case EditorView:
+			// Clicking on the selected cell cycles it between blank, dark and light
+			clickPoint(&m, msg.X, msg.Y, func(m *model) tea.Cmd {
+				m.editor.setCell(m.selectedPoint, cycleCell(m.editor.state.grid[m.selectedPoint.y][m.selectedPoint.x]))
+				return nil
+			})
+		case PointConfirmation:
+			return m, endTurn(&m)
+		case PassView:
+			return m, passTurn(&m)
+		}
+	case tea.MouseWheelUp:
+		if m.view == PointSelection && m.historyScroll < getMaxHistoryScroll(m) {
+			m.historyScroll++
+		}
+	case tea.MouseWheelDown:
+		if m.view == PointSelection && m.historyScroll > 0 {
+			m.historyScroll--
+		}
+	}
+
+	return m, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import "testing"
+
+func TestGetPointAt(t *testing.T) {
+	tests := []struct {
+		name            string
+		showCoordinates bool
+		displayMode     displayMode
+		x               int
+		y               int
+		want            vector2d
+		wantOK          bool
+	}{
+		{name: "top-left cell", x: 7, y: 3, want: vector2d{0, 0}, wantOK: true},
+		{name: "cell in the middle", x: 13, y: 6, want: vector2d{3, 3}, wantOK: true},
+		{name: "bottom-right cell", x: 21, y: 10, want: vector2d{7, 7}, wantOK: true},
+		{name: "space between cells", x: 8, y: 3},
+		{name: "border", x: 6, y: 3},
+		{name: "above the grid", x: 7, y: 2},
+		{name: "below the grid", x: 7, y: 11},
+		{name: "right of the grid", x: 23, y: 3},
+		{name: "after coordinate labels", showCoordinates: true, x: 9, y: 4, want: vector2d{0, 0}, wantOK: true},
+		{name: "on coordinate labels", showCoordinates: true, x: 7, y: 3},
+		{name: "inside padding for markers", displayMode: MarkersDisplay, x: 8, y: 3, want: vector2d{0, 0}, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := model{}
+			m.showCoordinates = tt.showCoordinates
+			m.displayMode = tt.displayMode
+
+			got, ok := getPointAt(m, tt.x, tt.y)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("got %s (%t), want %s (%t)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGetRadioButtonOptionIndex(t *testing.T) {
+	// Laid out as "Player mode: 1-Player [ ]; 2-Player [ ] (press P)"
+	options := []playerMode{OnePlayer, TwoPlayer}
+	tests := []struct {
+		name string
+		x    int
+		want int
+	}{
+		{name: "label", x: 0, want: -1},
+		{name: "start of first option", x: 13, want: 0},
+		{name: "end of first option", x: 24, want: 0},
+		{name: "separator", x: 25, want: -1},
+		{name: "space after separator", x: 26, want: -1},
+		{name: "start of last option", x: 27, want: 1},
+		{name: "end of last option", x: 38, want: 1},
+		{name: "key", x: 40, want: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getRadioButtonOptionIndex(options, "Player mode", tt.x); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"golang.org/x/exp/slices"
+	"math/rand"
+	"strings"
+)
+
+type openingMode int
+
+const (
+	StandardOpening openingMode = iota
+	// Starts from a random position reached by playing a few random moves, so that memorised openings don't help
+	RandomOpening
+)
+
+var allOpeningModes = []openingMode{StandardOpening, RandomOpening}
+
+func (om openingMode) String() string {
+	return [...]string{"Standard", "Random"}[om]
+}
+
+func toggleOpeningMode(om openingMode) openingMode {
+	return allOpeningModes[(slices.Index(allOpeningModes, om)+1)%len(allOpeningModes)]
+}
+
+// Seeds are kept short so that they're easy to share
+const maxSeed = 1000000
+
+// Random openings are this many moves long, chosen at random
+var openingLengths = []int{8, 12}
+
+// Random openings are only used if the engine evaluates them as being within this many disks of even
+const maxOpeningImbalance = 2
+
+var openingEngine = engine{depth: 4}
+
+func newSeed() int64 {
+	return rand.Int63n(maxSeed)
+}
+
+// Whether starting a game on the given board with the given opening involves anything random, and so needs a seed
+func needsSeed(b boardShape, om openingMode) bool {
+	return om == RandomOpening || b == RandomHolesBoard
+}
+
+// Returns the starting state for a game with the given rules, board and opening
+// The seed is used for anything random, so the same seed always gives the same start
+func newStartState(r rules, b boardShape, om openingMode, seed int64) gameState {
+	rng := rand.New(rand.NewSource(seed))
+
+	g := *newGrid(r)
+	addBlockedCells(&g, b, rng)
+	start := newGameState(g, DarkPlayer, r)
+	if om == RandomOpening {
+		return generateOpening(start, rng)
+	}
+	return start
+}
+
+// Returns a random opening position played from the given starting state that's roughly balanced between the players
+func generateOpening(start gameState, rng *rand.Rand) gameState {
+	for {
+		s := start
+		length := openingLengths[rng.Intn(len(openingLengths))]
+		for i := 0; i < length && len(s.legalMoves()) > 0; i++ {
+			moves := s.legalMoves()
+			s = s.play(moves[rng.Intn(len(moves))])
+		}
+
+		if len(s.legalMoves()) == 0 {
+			continue
+		}
+		if _, score := openingEngine.bestMove(s); score >= -maxOpeningImbalance && score <= maxOpeningImbalance {
+			return s
+		}
+	}
+}
+
+// Sets up a game starting from the board and opening in the settings, using the given seed for anything random
+func setUpStart(m *model, seed int64) {
+	s := newStartState(m.rules, m.board, m.opening, seed)
+	m.grid = s.grid
+	m.currentPlayer = s.player
+	m.supply = s.supply
+	m.availablePoints = s.legalMoves()
+	m.record = newGameRecord(s.grid, s.player, s.rules)
+	m.record.board = m.board
+	m.record.opening = m.opening
+	if needsSeed(m.board, m.opening) {
+		m.record.seed = &seed
+	}
+}
+
+// Describes how the game started if it wasn't the usual way, or returns an empty string otherwise
+func createStartText(gr gameRecord) string {
+	parts := make([]string, 0, 3)
+	if gr.board != StandardBoard {
+		parts = append(parts, fmt.Sprintf("Board: %s", gr.board))
+	}
+	if gr.opening == RandomOpening {
+		parts = append(parts, "random opening")
+	}
+	if gr.seed != nil {
+		parts = append(parts, fmt.Sprintf("seed %d", *gr.seed))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+
+	text := strings.Join(parts, "; ")
+	return strings.ToUpper(text[:1]) + text[1:]
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const blankSymbol = "."
+const blockedSymbol = "#"
+
+// Positions are written as a symbol for each cell in row-major order (X for dark, O for light, . for blank and # for
+// blocked), followed by the symbol of the player to move, e.g. the Othello starting position is written as
+// "...........................OX......XO........................... X"
+func (s gameState) String() string {
+	var builder strings.Builder
+	for _, row := range s.grid {
+		for _, cell := range row {
+			switch cell {
+			case Blank:
+				builder.WriteString(blankSymbol)
+			case Blocked:
+				builder.WriteString(blockedSymbol)
+			default:
+				builder.WriteString(cell.toSymbol())
+			}
+		}
+	}
+	builder.WriteString(" ")
+	builder.WriteString(s.player.toSymbol())
+	return builder.String()
+}
+
+func parseSymbol(r rune) (player, bool) {
+	switch unicode.ToUpper(r) {
+	case 'X':
+		return DarkPlayer, true
+	case 'O':
+		return LightPlayer, true
+	case '-', '.':
+		return Blank, true
+	case '#':
+		return Blocked, true
+	default:
+		return Blank, false
+	}
+}
+
+// Removes any comment (anything after a "#") from the text, other than within a position at the start of the text (as
+// written by `gameState.String`), where "#" is a blocked cell
+func cutComment(s string) string {
+	s = strings.TrimSpace(s)
+	position, rest, _ := strings.Cut(s, " ")
+	if len(position) != gridWidth*gridHeight {
+		s, _, _ = strings.Cut(s, "#")
+		return strings.TrimSpace(s)
+	}
+
+	rest, _, _ = strings.Cut(rest, "#")
+	return strings.TrimSpace(position + " " + rest)
+}
+
+// Parses a position written by `gameState.String`; whitespace is ignored, and dark is to move if the player to move is
+// left out
+func parsePosition(s string, r rules) (gameState, error) {
+	symbols := make([]rune, 0, gridWidth*gridHeight+1)
+	for _, c := range s {
+		if !unicode.IsSpace(c) {
+			symbols = append(symbols, c)
+		}
+	}
+
+	if len(symbols) != gridWidth*gridHeight && len(symbols) != gridWidth*gridHeight+1 {
+		return gameState{}, fmt.Errorf("invalid position %q: expected %d cells followed by the player to move", s,
+			gridWidth*gridHeight)
+	}
+
+	state := gameState{player: DarkPlayer, rules: r}
+	for i, c := range symbols[:gridWidth*gridHeight] {
+		cell, ok := parseSymbol(c)
+		if !ok {
+			return gameState{}, fmt.Errorf("invalid position %q: unknown cell %q", s, c)
+		}
+		state.grid[i/gridWidth][i%gridWidth] = cell
+	}
+
+	if len(symbols) > gridWidth*gridHeight {
+		p, ok := parseSymbol(symbols[gridWidth*gridHeight])
+		if !ok || !isDisk(p) {
+			return gameState{}, fmt.Errorf("invalid position %q: unknown player to move %q", s,
+				symbols[gridWidth*gridHeight])
+		}
+		state.player = p
+	}
+
+	state.supply = newDiskSupply(state.grid, state.player)
+	return state, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import "testing"
+
+func parseTestPosition(t *testing.T, s string, r rules) gameState {
+	t.Helper()
+	state, err := parsePosition(s, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return state
+}
+
+func TestParsePositionRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		position string
+		rules    rules
+	}{
+		{
+			name:     "Othello start",
+			position: "...........................OX......XO........................... X",
+			rules:    OthelloRules,
+		},
+		{
+			name:     "empty Reversi board",
+			position: "................................................................ X",
+			rules:    ReversiRules,
+		},
+		{
+			name:     "light to move",
+			position: "...................X.......XX......XO........................... O",
+			rules:    OthelloRules,
+		},
+		{
+			name:     "blocked cells",
+			position: "###..###.#....#..........................................#....#. X",
+			rules:    AntiReversiRules,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := parseTestPosition(t, tt.position, tt.rules)
+			if got := s.String(); got != tt.position {
+				t.Errorf("got %q, want %q", got, tt.position)
+			}
+			if s.rules != tt.rules {
+				t.Errorf("got rules %s, want %s", s.rules, tt.rules)
+			}
+		})
+	}
+}
+
+func TestParsePosition(t *testing.T) {
+	s := parseTestPosition(t, `
+		........
+		........
+		........
+		...ox...
+		...xo...
+		........
+		........
+		--------`, OthelloRules)
+
+	if want := *newGrid(OthelloRules); s.grid != want {
+		t.Errorf("got grid %s, want the Othello start", s)
+	}
+	if s.player != DarkPlayer {
+		t.Errorf("got %s to move, want %s when the player to move is left out", s.player, DarkPlayer)
+	}
+}
+
+func TestParsePositionErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		position string
+	}{
+		{name: "too few cells", position: "...........................OX......XO.........................."},
+		{name: "too many cells", position: "...........................OX......XO............................ X X"},
+		{name: "unknown cell", position: "...........................OX......XZ........................... X"},
+		{name: "blank player to move", position: "...........................OX......XO........................... ."},
+		{name: "blocked player to move", position: "...........................OX......XO........................... #"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parsePosition(tt.position, OthelloRules); err == nil {
+				t.Errorf("got no error for %q", tt.position)
+			}
+		})
+	}
+}
+
+func TestCutComment(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "no comment", text: "  f5 d6  ", want: "f5 d6"},
+		{name: "comment", text: "f5 d6 # opening", want: "f5 d6"},
+		{name: "whole line comment", text: "# Rules: Reversi", want: ""},
+		{
+			name: "blocked cells in a position",
+			text: "###..###.#....#..........................................#....#. X 1200 # c1",
+			want: "###..###.#....#..........................................#....#. X 1200",
+		},
+		{
+			name: "blocked last cell in a position",
+			text: "...........................OX......XO..........................# O",
+			want: "...........................OX......XO..........................# O",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cutComment(tt.text); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
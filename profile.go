@@ -0,0 +1,355 @@
+package main
+
+import (
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize/english"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"strings"
+)
+
+const defaultProfileName = "Player"
+
+type gameOutcome int
+
+const (
+	Win gameOutcome = iota
+	Loss
+	Draw
+)
+
+type resultCounts struct {
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Draws  int `json:"draws"`
+}
+
+func (rc resultCounts) total() int {
+	return rc.Wins + rc.Losses + rc.Draws
+}
+
+type profileStats struct {
+	GamesPlayed int `json:"gamesPlayed"`
+	// Results by rules and then by opponent, i.e. the computer's difficulty
+	Results map[string]map[string]resultCounts `json:"results"`
+	// Sum of the profile's disks minus the opponent's disks at the end of each game, or the other way round under
+	// Anti-Reversi rules, so that it's positive for wins
+	TotalDiskDifferential int `json:"totalDiskDifferential"`
+	WinStreak             int `json:"winStreak"`
+	LongestWinStreak      int `json:"longestWinStreak"`
+}
+
+type profile struct {
+	Stats  profileStats `json:"stats"`
+	Rating rating       `json:"rating"`
+	// Identified by `puzzle.getID`
+	SolvedPuzzles []string `json:"solvedPuzzles"`
+}
+
+// The result of a finished game from the point of view of the profile's player
+type gameResult struct {
+	rules            rules
+	opponent         difficulty
+	outcome          gameOutcome
+	diskDifferential int
+}
+
+// Returns the result of the finished game for the human player in a 1-player game, who always plays dark
+func newGameResult(m model) gameResult {
+	scores := computeScores(m.grid)
+	result := gameResult{
+		rules:            m.rules,
+		opponent:         m.difficulty,
+		diskDifferential: scores[DarkPlayer] - scores[LightPlayer],
+	}
+	if m.rules.isMisere() {
+		result.diskDifferential = -result.diskDifferential
+	}
+
+	switch {
+	case m.clock.isFlagged() && m.clock.flagged == DarkPlayer:
+		result.outcome = Loss
+	case m.clock.isFlagged():
+		result.outcome = Win
+	case result.diskDifferential > 0:
+		result.outcome = Win
+	case result.diskDifferential < 0:
+		result.outcome = Loss
+	default:
+		result.outcome = Draw
+	}
+	return result
+}
+
+func (ps *profileStats) addResult(result gameResult) {
+	if ps.Results == nil {
+		ps.Results = make(map[string]map[string]resultCounts)
+	}
+	if ps.Results[result.rules.String()] == nil {
+		ps.Results[result.rules.String()] = make(map[string]resultCounts)
+	}
+
+	counts := ps.Results[result.rules.String()][result.opponent.String()]
+	switch result.outcome {
+	case Win:
+		counts.Wins++
+		ps.WinStreak++
+		if ps.WinStreak > ps.LongestWinStreak {
+			ps.LongestWinStreak = ps.WinStreak
+		}
+	case Loss:
+		counts.Losses++
+		ps.WinStreak = 0
+	case Draw:
+		counts.Draws++
+		ps.WinStreak = 0
+	}
+	ps.Results[result.rules.String()][result.opponent.String()] = counts
+
+	ps.GamesPlayed++
+	ps.TotalDiskDifferential += result.diskDifferential
+}
+
+// Returns the results added up over all rules and opponents
+func (ps profileStats) getTotals() resultCounts {
+	var totals resultCounts
+	for _, opponents := range ps.Results {
+		for _, counts := range opponents {
+			totals.Wins += counts.Wins
+			totals.Losses += counts.Losses
+			totals.Draws += counts.Draws
+		}
+	}
+	return totals
+}
+
+func (ps profileStats) getAverageDiskDifferential() float64 {
+	if ps.GamesPlayed == 0 {
+		return 0
+	}
+	return float64(ps.TotalDiskDifferential) / float64(ps.GamesPlayed)
+}
+
+const profilesFileName = "profiles.json"
+
+// Reads every profile by name; a missing profiles file isn't an error
+func loadProfiles() (map[string]*profile, error) {
+	profiles := make(map[string]*profile)
+	if err := loadDataFile(profilesFileName, &profiles); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+func saveProfiles(profiles map[string]*profile) error {
+	return saveDataFile(profilesFileName, profiles)
+}
+
+// Loads the profiles, applies the given change to the named profile (creating it if it doesn't exist yet) and saves
+// them again, returning the changed profile
+// The change is made while holding dataFilesMutex, so it can safely update other files in the game's directory too;
+// if it returns an error, the profiles aren't saved
+func updateProfile(name string, update func(p *profile) error) (profile, error) {
+	dataFilesMutex.Lock()
+	defer dataFilesMutex.Unlock()
+
+	profiles, err := loadProfiles()
+	if err != nil {
+		return profile{}, err
+	}
+
+	if profiles[name] == nil {
+		profiles[name] = &profile{}
+	}
+	if err := update(profiles[name]); err != nil {
+		return profile{}, err
+	}
+
+	return *profiles[name], saveProfiles(profiles)
+}
+
+type profileLoadedMsg struct {
+	profile         profile
+	computerRatings computerRatings
+	// Whether the profile was loaded after recording a game, rather than just to show it
+	gameRecorded bool
+	err          error
+}
+
+func loadProfileCmd(name string) tea.Cmd {
+	return func() tea.Msg {
+		profiles, err := loadProfiles()
+		if err != nil {
+			return profileLoadedMsg{err: err}
+		}
+
+		cr, err := loadComputerRatings()
+		if err != nil {
+			return profileLoadedMsg{err: err}
+		}
+
+		var p profile
+		if profiles[name] != nil {
+			p = *profiles[name]
+		}
+		return profileLoadedMsg{profile: p, computerRatings: cr}
+	}
+}
+
+func recordGameCmd(name string, result gameResult) tea.Cmd {
+	return func() tea.Msg {
+		var cr computerRatings
+		p, err := updateProfile(name, func(p *profile) error {
+			var err error
+			if cr, err = loadComputerRatings(); err != nil {
+				return err
+			}
+
+			p.Stats.addResult(result)
+			updateRatings(&p.Rating, cr, result.opponent, result.outcome)
+			return saveComputerRatings(cr)
+		})
+		if err != nil {
+			return profileLoadedMsg{gameRecorded: true, err: err}
+		}
+
+		return profileLoadedMsg{profile: p, computerRatings: cr, gameRecorded: true}
+	}
+}
+
+// Ends the game, recording the result in the profile's statistics if it was played against the computer
+// Games from a position set up by hand aren't recorded, as the position could be lopsided
+func endGame(m *model) tea.Cmd {
+	m.view = GameOverView
+	if m.playerMode != OnePlayer || !m.record.hasUsualStart() {
+		return nil
+	}
+
+	return recordGameCmd(m.profileName, newGameResult(*m))
+}
+
+// Shows the statistics, returning to the current view afterwards
+func openStatsView(m *model) tea.Cmd {
+	m.statsReturnView = m.view
+	m.view = StatsView
+	return loadProfileCmd(m.profileName)
+}
+
+func createStatsView(m model, maxWidth int) string {
+	t := m.getTheme()
+	stats := m.profile.Stats
+
+	textStrings := []string{t.accent1Text.Render(fmt.Sprintf("Statistics for %s", m.profileName)), ""}
+	if m.profileErr != nil {
+		textStrings = append(textStrings, t.errorText.Render(fmt.Sprintf("Could not load statistics: %v", m.profileErr)))
+	} else if stats.GamesPlayed == 0 {
+		textStrings = append(textStrings, "No games played yet.")
+	} else {
+		totals := stats.getTotals()
+		textStrings = append(textStrings,
+			fmt.Sprintf("Games played: %d", stats.GamesPlayed),
+			fmt.Sprintf("Wins: %d; losses: %d; draws: %d", totals.Wins, totals.Losses, totals.Draws),
+			fmt.Sprintf("Average disk differential: %+.1f", stats.getAverageDiskDifferential()),
+			fmt.Sprintf("Longest win streak: %d (current: %d)", stats.LongestWinStreak, stats.WinStreak),
+			"",
+			createResultsTable(stats, t),
+		)
+	}
+	if m.profileErr == nil {
+		textStrings = append(textStrings, "", fmt.Sprintf("Puzzles solved: %d", len(m.profile.SolvedPuzzles)), "")
+		textStrings = append(textStrings, createRatingText(m, maxWidth)...)
+	}
+	textStrings = append(textStrings, "", t.secondaryText.Render(formatHelpEntry("any key", "back")))
+
+	return lipgloss.NewStyle().
+		Width(maxWidth).
+		Render(lipgloss.JoinVertical(lipgloss.Left, textStrings...))
+}
+
+// Lists the wins, losses and draws against each difficulty under each rules, with the easiest first
+func createResultsTable(stats profileStats, t theme) string {
+	opponentOrder := make([]string, 0, len(difficulties))
+	for _, d := range difficulties {
+		opponentOrder = append(opponentOrder, d.String())
+	}
+	getOpponentIndex := func(opponent string) int {
+		if i := slices.Index(opponentOrder, opponent); i >= 0 {
+			return i
+		}
+		return len(opponentOrder)
+	}
+
+	rulesNames := maps.Keys(stats.Results)
+	slices.Sort(rulesNames)
+
+	// Columns are as wide as their longest label, as some rules have long names
+	rulesWidth, opponentWidth := len("Rules"), len("Opponent")
+	for _, rulesName := range rulesNames {
+		if len(rulesName) > rulesWidth {
+			rulesWidth = len(rulesName)
+		}
+		for opponent := range stats.Results[rulesName] {
+			if len(opponent) > opponentWidth {
+				opponentWidth = len(opponent)
+			}
+		}
+	}
+
+	rows := []string{t.secondaryText.Render(fmt.Sprintf("%-*s %-*s %5s %5s %5s", rulesWidth, "Rules", opponentWidth,
+		"Opponent", "Won", "Lost", "Drawn"))}
+	for _, rulesName := range rulesNames {
+		opponents := maps.Keys(stats.Results[rulesName])
+		slices.SortFunc(opponents, func(a string, b string) bool {
+			return getOpponentIndex(a) < getOpponentIndex(b)
+		})
+
+		for _, opponent := range opponents {
+			counts := stats.Results[rulesName][opponent]
+			if counts.total() == 0 {
+				continue
+			}
+			rows = append(rows, fmt.Sprintf("%-*s %-*s %5d %5d %5d", rulesWidth, rulesName, opponentWidth, opponent,
+				counts.Wins, counts.Losses, counts.Draws))
+		}
+	}
+	return strings.Join(rows, "\n")
+}
+
+const ratingGraphHeight = 6
+
+// Describes the profile's rating and the computer's ratings, with a graph of how the profile's rating has changed
+func createRatingText(m model, maxWidth int) []string {
+	t := m.getTheme()
+	r := m.profile.Rating
+	value := r.getValue(initialRating)
+
+	computerRatingStrings := make([]string, 0, len(difficulties))
+	for _, d := range difficulties {
+		computerRatingStrings = append(computerRatingStrings, fmt.Sprintf("%s: %.0f", d, m.computerRatings.getValue(d)))
+	}
+
+	textStrings := []string{
+		fmt.Sprintf("Rating: %.0f (%s)", value, english.Plural(r.Games, "game", "")),
+		fmt.Sprintf("Computer ratings: %s", strings.Join(computerRatingStrings, "; ")),
+		fmt.Sprintf("Suggested difficulty: %s", suggestDifficulty(value, m.computerRatings)),
+	}
+	if len(r.History) > 1 {
+		// Graphs can't be read out by screen readers
+		if m.displayMode == ScreenReaderDisplay {
+			recent := r.History
+			if len(recent) > 10 {
+				recent = recent[len(recent)-10:]
+			}
+			recentStrings := make([]string, 0, len(recent))
+			for _, value := range recent {
+				recentStrings = append(recentStrings, fmt.Sprintf("%.0f", value))
+			}
+			textStrings = append(textStrings, fmt.Sprintf("Recent ratings: %s", strings.Join(recentStrings, ", ")))
+		} else {
+			textStrings = append(textStrings, "", createRatingGraph(r.History, maxWidth-8, ratingGraphHeight, t))
+		}
+	}
+	return textStrings
+}
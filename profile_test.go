@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestUpdateProfileConcurrently(t *testing.T) {
+	useTempConfigDir(t)
+
+	const updates = 20
+	var wg sync.WaitGroup
+	errs := make([]error, updates)
+	for i := 0; i < updates; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = updateProfile("Ann", func(p *profile) error {
+				p.SolvedPuzzles = append(p.SolvedPuzzles, fmt.Sprint(i))
+				return nil
+			})
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	profiles, err := loadProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(profiles["Ann"].SolvedPuzzles); got != updates {
+		t.Errorf("got %d solved puzzles, want %d", got, updates)
+	}
+}
@@ -0,0 +1,448 @@
+package main
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize/english"
+	"golang.org/x/exp/slices"
+	"io"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed puzzles.txt
+var embeddedPuzzles string
+
+// Puzzles are solved exactly, which is only quick enough with this many empty cells or fewer
+const maxPuzzleEmpties = 10
+
+var puzzleEngine = engine{exactEmpties: maxPuzzleEmpties}
+
+// A position in which the player to move has to find the best move
+type puzzle struct {
+	state  gameState
+	rating int
+	title  string
+}
+
+// Identifies the puzzle in the list of puzzles a profile has solved
+func (p puzzle) getID() string {
+	return p.state.String()
+}
+
+// Reads puzzles, one per line, each consisting of a position and the player to move (as written by
+// `gameState.String`), a difficulty rating and an optional title
+// As with transcripts, rules can be given using a "Rules:" header, which applies to the puzzles after it, and anything
+// after a "#" is a comment
+func parsePuzzles(r io.Reader) ([]puzzle, error) {
+	puzzles := make([]puzzle, 0)
+	currentRules := OthelloRules
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := cutComment(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if key, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(strings.TrimSpace(key), "rules") {
+			r, err := parseRules(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			currentRules = r
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("line %d: expected a position, the player to move and a rating", lineNumber)
+		}
+
+		state, err := parsePosition(fields[0]+" "+fields[1], currentRules)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		if len(state.legalMoves()) == 0 {
+			return nil, fmt.Errorf("line %d: %s has no legal moves", lineNumber, state.player)
+		}
+		if state.countEmpties() > maxPuzzleEmpties {
+			return nil, fmt.Errorf("line %d: puzzles can have at most %d empty cells", lineNumber, maxPuzzleEmpties)
+		}
+
+		rating, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid rating %q", lineNumber, fields[2])
+		}
+
+		puzzles = append(puzzles, puzzle{
+			state:  state,
+			rating: rating,
+			title:  strings.Join(fields[3:], " "),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return puzzles, nil
+}
+
+var builtInPuzzles = mustParsePuzzles(embeddedPuzzles)
+
+func mustParsePuzzles(s string) []puzzle {
+	puzzles, err := parsePuzzles(strings.NewReader(s))
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in puzzles: %v", err))
+	}
+	return puzzles
+}
+
+// Reads the puzzles in the given file, or returns the built-in puzzles if the path is empty
+func readPuzzleFile(path string) ([]puzzle, error) {
+	if path == "" {
+		return builtInPuzzles, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	puzzles, err := parsePuzzles(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(puzzles) == 0 {
+		return nil, fmt.Errorf("%s: no puzzles", path)
+	}
+	return puzzles, nil
+}
+
+// Scores are the final disk differential with perfect play, from the perspective of the player to move
+type puzzleCheck struct {
+	best      vector2d
+	bestScore int
+	score     int
+}
+
+// Any move as good as the best move counts, as some puzzles have more than one solution
+func (c puzzleCheck) isCorrect() bool {
+	return c.score == c.bestScore
+}
+
+type puzzleSession struct {
+	puzzles []puzzle
+	index   int
+	// The move played in the current puzzle, if any
+	attempt *vector2d
+	// Set once the move played has been checked
+	check *puzzleCheck
+	// Used to discard checks of previous attempts
+	id int
+}
+
+type puzzleCheckedMsg struct {
+	id    int
+	check puzzleCheck
+}
+
+func (ps puzzleSession) getPuzzle() puzzle {
+	return ps.puzzles[ps.index]
+}
+
+// Returns the grid after the move played, or the puzzle's position if a move hasn't been played yet
+func (ps puzzleSession) getGrid() grid {
+	if ps.attempt == nil {
+		return ps.getPuzzle().state.grid
+	}
+	return ps.getPuzzle().state.play(*ps.attempt).grid
+}
+
+func checkPuzzleCmd(id int, s gameState, p vector2d) tea.Cmd {
+	return func() tea.Msg {
+		best, bestScore := puzzleEngine.bestMove(s)
+		score := puzzleEngine.scoreChild(s, s.play(p), s.countEmpties()-1, -maxScore-1, maxScore+1)
+		return puzzleCheckedMsg{id: id, check: puzzleCheck{best: best, bestScore: bestScore, score: score}}
+	}
+}
+
+func recordPuzzleSolvedCmd(name string, id string) tea.Cmd {
+	return func() tea.Msg {
+		p, err := updateProfile(name, func(p *profile) error {
+			if !slices.Contains(p.SolvedPuzzles, id) {
+				p.SolvedPuzzles = append(p.SolvedPuzzles, id)
+			}
+			return nil
+		})
+		return profileLoadedMsg{profile: p, err: err}
+	}
+}
+
+// Shows the given puzzles in order of difficulty, starting with the easiest one the profile hasn't solved yet
+func startPuzzles(m *model, puzzles []puzzle) {
+	puzzles = slices.Clone(puzzles)
+	slices.SortStableFunc(puzzles, func(a puzzle, b puzzle) bool {
+		return a.rating < b.rating
+	})
+
+	m.puzzles = puzzleSession{puzzles: puzzles, id: m.puzzles.id}
+	for i, p := range puzzles {
+		if !slices.Contains(m.profile.SolvedPuzzles, p.getID()) {
+			m.puzzles.index = i
+			break
+		}
+	}
+
+	m.view = PuzzleView
+	showPuzzle(m)
+}
+
+// Sets up the board for the current puzzle
+func showPuzzle(m *model) {
+	s := m.puzzles.getPuzzle().state
+	m.puzzles.attempt = nil
+	m.puzzles.check = nil
+	m.puzzles.id++
+
+	m.grid = s.grid
+	m.currentPlayer = s.player
+	m.supply = s.supply
+	m.availablePoints = s.legalMoves()
+	m.record = newGameRecord(s.grid, s.player, s.rules)
+	m.disksFlipped = nil
+}
+
+// Plays the selected point as the answer to the current puzzle, if it's a legal move
+func attemptPuzzle(m *model) tea.Cmd {
+	if m.puzzles.attempt != nil || !slices.Contains(m.availablePoints, m.selectedPoint) {
+		return nil
+	}
+
+	p := m.selectedPoint
+	m.puzzles.attempt = &p
+	return checkPuzzleCmd(m.puzzles.id, m.puzzles.getPuzzle().state, p)
+}
+
+func updatePuzzleChecked(m *model, msg puzzleCheckedMsg) tea.Cmd {
+	// Discard checks of moves in other puzzles, or from before the puzzle was retried
+	if msg.id != m.puzzles.id || m.view != PuzzleView {
+		return nil
+	}
+
+	m.puzzles.check = &msg.check
+	if !msg.check.isCorrect() {
+		return nil
+	}
+
+	return recordPuzzleSolvedCmd(m.profileName, m.puzzles.getPuzzle().getID())
+}
+
+func countSolvedPuzzles(puzzles []puzzle, solved []string) int {
+	count := 0
+	for _, p := range puzzles {
+		if slices.Contains(solved, p.getID()) {
+			count++
+		}
+	}
+	return count
+}
+
+func createPuzzleView(m model, maxWidth int) string {
+	t := m.getTheme()
+	ps := m.puzzles
+	p := ps.getPuzzle()
+
+	heading := fmt.Sprintf("Puzzle %d of %d (%s rules, rating %d)", ps.index+1, len(ps.puzzles), p.state.rules, p.rating)
+	textStrings := []string{t.accent1Text.Render(heading)}
+	if p.title != "" {
+		textStrings = append(textStrings, p.title)
+	}
+	solvedText := fmt.Sprintf("Solved %d of %d", countSolvedPuzzles(ps.puzzles, m.profile.SolvedPuzzles), len(ps.puzzles))
+	if slices.Contains(m.profile.SolvedPuzzles, p.getID()) {
+		solvedText += ", including this one"
+	}
+	textStrings = append(textStrings, t.secondaryText.Render(solvedText), "",
+		fmt.Sprintf("%s (%s) to play: find the best move", p.state.player, p.state.player.toSymbol()))
+
+	switch {
+	case ps.attempt == nil:
+		if slices.Contains(m.availablePoints, m.selectedPoint) {
+			textStrings = append(textStrings, t.successText.Render(fmt.Sprintf("Can place disk at %s", m.selectedPoint)))
+		} else {
+			textStrings = append(textStrings, t.errorText.Render(fmt.Sprintf("Cannot place disk at %s", m.selectedPoint)))
+		}
+	case ps.check == nil:
+		textStrings = append(textStrings, fmt.Sprintf("Checking %s...", ps.attempt))
+	case ps.check.isCorrect():
+		textStrings = append(textStrings,
+			t.successText.Render(fmt.Sprintf("Correct! %s is the best move (%+d)", ps.attempt, ps.check.score)))
+	default:
+		textStrings = append(textStrings,
+			t.errorText.Render(fmt.Sprintf("Not quite: %s gives %+d, but %s gives %+d", ps.attempt, ps.check.score,
+				ps.check.best, ps.check.bestScore)))
+	}
+	if ps.check != nil {
+		textStrings = append(textStrings,
+			t.secondaryText.Render("Scores are the final disk differential with perfect play"))
+	}
+
+	textStrings = append(textStrings, "", t.secondaryText.Render(createPuzzleHelp(m.keys, ps.attempt == nil)))
+
+	return lipgloss.NewStyle().
+		Width(maxWidth).
+		Render(lipgloss.JoinVertical(lipgloss.Left, textStrings...))
+}
+
+// Plays through the puzzles in the given file, or the built-in puzzles
+func runPuzzles(args []string, s settings, _ io.Writer) error {
+	flags := newFlagSet("puzzles", "[FILE]")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		return usageError(flags)
+	}
+
+	puzzles, err := readPuzzleFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	profiles, err := loadProfiles()
+	if err != nil {
+		return err
+	}
+
+	m := createInitialModel(s)
+	if p := profiles[s.profileName]; p != nil {
+		m.profile = *p
+	}
+	startPuzzles(&m, puzzles)
+	return runProgram(m)
+}
+
+// Estimates how hard a puzzle is, on the same scale as player ratings, from how many moves there are to choose from,
+// how far ahead they have to be read and whether the obvious move of flipping the most disks is the solution
+func estimatePuzzleRating(s gameState, best vector2d) int {
+	rating := 600 + 60*s.countEmpties() + 20*len(s.legalMoves())
+	if EasyDifficulty.chooseMove(s) != best {
+		rating += 200
+	}
+	return rating
+}
+
+// Returns a puzzle for the given position if it has a single clear best move: either the only winning move, or a move
+// at least the given number of disks better than any other
+func findPuzzle(s gameState, threshold int) (puzzle, vector2d, int, bool) {
+	moves := s.legalMoves()
+	if len(moves) < 2 || s.countEmpties() > maxPuzzleEmpties {
+		return puzzle{}, vector2d{}, 0, false
+	}
+
+	scores := puzzleEngine.evaluateMoves(s)
+	slices.SortStableFunc(moves, func(a vector2d, b vector2d) bool {
+		return scores[a] > scores[b]
+	})
+
+	best, second := scores[moves[0]], scores[moves[1]]
+	if best == second || !((best > 0 && second <= 0) || best-second >= threshold) {
+		return puzzle{}, vector2d{}, 0, false
+	}
+
+	return puzzle{state: s, rating: estimatePuzzleRating(s, moves[0])}, moves[0], best, true
+}
+
+// Writes puzzles in the format read by `parsePuzzles`, with each puzzle's solution in a comment
+func writePuzzles(w io.Writer, puzzles []puzzle, solutions []string) error {
+	var builder strings.Builder
+	for i, p := range puzzles {
+		if i == 0 || p.state.rules != puzzles[i-1].state.rules {
+			builder.WriteString(fmt.Sprintf("Rules: %s\n", p.state.rules))
+		}
+
+		builder.WriteString(fmt.Sprintf("%s %d", p.state, p.rating))
+		if p.title != "" {
+			builder.WriteString(" " + p.title)
+		}
+		builder.WriteString(fmt.Sprintf(" # %s\n", solutions[i]))
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// Finds puzzles in the given saved games, or in games played between computer players if there aren't any
+func runGenPuzzles(args []string, s settings, w io.Writer) error {
+	flags := newFlagSet("gen-puzzles", "[flags] [FILE...]")
+	rulesName := addRulesFlag(flags, s)
+	games := flags.Int("games", 20, "number of games to play between computer players if no files are given")
+	randomMoves := flags.Int("random", 8, "number of random moves at the start of each game, so that games differ")
+	seed := flags.Int64("seed", time.Now().UnixNano(), "seed for the random moves")
+	minEmpties := flags.Int("min-empties", 4, "fewest empty cells a puzzle can have")
+	maxEmpties := flags.Int("max-empties", maxPuzzleEmpties, "most empty cells a puzzle can have")
+	threshold := flags.Int("threshold", 8,
+		"number of disks the best move must beat every other move by, if it isn't the only winning move")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *maxEmpties > maxPuzzleEmpties {
+		return fmt.Errorf("puzzles can have at most %d empty cells", maxPuzzleEmpties)
+	}
+
+	var records []gameRecord
+	if flags.NArg() > 0 {
+		for _, path := range flags.Args() {
+			gr, err := readTranscriptFile(path)
+			if err != nil {
+				return err
+			}
+			records = append(records, gr)
+		}
+	} else {
+		r, err := parseRules(*rulesName)
+		if err != nil {
+			return err
+		}
+
+		rng := rand.New(rand.NewSource(*seed))
+		playerDifficulties := map[player]difficulty{DarkPlayer: MediumDifficulty, LightPlayer: MediumDifficulty}
+		for i := 0; i < *games; i++ {
+			gr, err := playSelfplayGame(r, playerDifficulties, *randomMoves, rng)
+			if err != nil {
+				return err
+			}
+			records = append(records, gr)
+		}
+	}
+
+	puzzles := make([]puzzle, 0)
+	solutions := make([]string, 0)
+	for _, gr := range records {
+		for _, state := range gr.states() {
+			empties := state.countEmpties()
+			if empties < *minEmpties || empties > *maxEmpties {
+				continue
+			}
+			if slices.ContainsFunc(puzzles, func(p puzzle) bool { return p.state == state }) {
+				continue
+			}
+
+			if p, best, score, ok := findPuzzle(state, *threshold); ok {
+				puzzles = append(puzzles, p)
+				solutions = append(solutions, fmt.Sprintf("%s %+d", best, score))
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "# %s found in %s\n", english.Plural(len(puzzles), "puzzle", ""),
+		english.Plural(len(records), "game", ""))
+	return writePuzzles(w, puzzles, solutions)
+}
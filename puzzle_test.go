@@ -0,0 +1,131 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuiltInPuzzlesHaveClearSolutions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("solving every built-in puzzle is slow")
+	}
+	if len(builtInPuzzles) == 0 {
+		t.Fatal("no built-in puzzles")
+	}
+
+	for _, p := range builtInPuzzles {
+		if _, _, _, ok := findPuzzle(p.state, 8); !ok {
+			t.Errorf("puzzle %s has no single clear best move", p.getID())
+		}
+	}
+}
+
+func TestParsePuzzles(t *testing.T) {
+	got, err := parsePuzzles(strings.NewReader(`
+		# Comments and blank lines are ignored
+		.XXXXXXXXXOOXO.OXXOOOOOOXXOXXOXOXXOOXOXOXOXOXOOOX.OOOOO.X.OOOO.O X 1200 Corner grab
+
+		Rules: Reversi
+		.XXXXXXXXXOOXO.OXXOOOOOOXXOXXOXOXXOOXOXOXOXOXOOOX.OOOOO.X.OOOO.O O 1500
+	`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []puzzle{
+		{
+			state: parseTestPosition(t, ".XXXXXXXXXOOXO.OXXOOOOOOXXOXXOXOXXOOXOXOXOXOXOOOX.OOOOO.X.OOOO.O X",
+				OthelloRules),
+			rating: 1200,
+			title:  "Corner grab",
+		},
+		{
+			state: parseTestPosition(t, ".XXXXXXXXXOOXO.OXXOOOOOOXXOXXOXOXXOOXOXOXOXOXOOOX.OOOOO.X.OOOO.O O",
+				ReversiRules),
+			rating: 1500,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestFindPuzzle(t *testing.T) {
+	tests := []struct {
+		name     string
+		position string
+		want     bool
+	}{
+		{
+			name:     "clear best move",
+			position: ".XXXXXXXXXOOXO.OXXOOOOOOXXOXXOXOXXOOXOXOXOXOXOOOX.OOOOO.X.OOOO.O X",
+			want:     true,
+		},
+		{name: "only one legal move", position: ".OXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX X"},
+		{name: "too many empty cells", position: "...........................OX......XO........................... X"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := parseTestPosition(t, tt.position, OthelloRules)
+			if _, _, _, ok := findPuzzle(s, 8); ok != tt.want {
+				t.Errorf("got %t, want %t", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestPuzzlesRoundTrip(t *testing.T) {
+	puzzles := []puzzle{
+		builtInPuzzles[0],
+		{
+			state: parseTestPosition(t, ".XXXXXXXXXOOXO.OXXOOOOOOXXOXXOXOXXOOXOXOXOXOXOOOX.OOOOO.X.OOOO.O O",
+				ReversiRules),
+			rating: 1500,
+			title:  "Under Reversi rules",
+		},
+		{
+			state: parseTestPosition(t, "#XXXXXX#XXOOOOOXXXOXXOXXXXOOXXXOXXXOXXOOXXXXOXOXXXXXOXOX#......# O",
+				AntiReversiRules),
+			rating: 1500,
+			title:  "Blocked corners",
+		},
+	}
+	solutions := []string{"first", "second", "third"}
+
+	var builder strings.Builder
+	if err := writePuzzles(&builder, puzzles, solutions); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := parsePuzzles(strings.NewReader(builder.String()))
+	if err != nil {
+		t.Fatalf("%v\n%s", err, builder.String())
+	}
+	if !reflect.DeepEqual(got, puzzles) {
+		t.Errorf("puzzles read back differently:\n%s", builder.String())
+	}
+}
+
+func TestParsePuzzlesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		puzzles string
+	}{
+		{name: "unknown rules", puzzles: "Rules: Chess\n"},
+		{name: "missing rating", puzzles: ".XXXXXXXXXOOXO.OXXOOOOOOXXOXXOXOXXOOXOXOXOXOXOOOX.OOOOO.X.OOOO.O X\n"},
+		{name: "invalid rating", puzzles: ".XXXXXXXXXOOXO.OXXOOOOOOXXOXXOXOXXOOXOXOXOXOXOOOX.OOOOO.X.OOOO.O X hard\n"},
+		{name: "invalid position", puzzles: ".XXXXXXXXXOOXO.OXX X 1000\n"},
+		{name: "too many empty cells", puzzles: "...........................OX......XO........................... X 1000\n"},
+		{name: "no legal moves", puzzles: "XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX. X 1000\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parsePuzzles(strings.NewReader(tt.puzzles)); err == nil {
+				t.Errorf("got no error for %q", tt.puzzles)
+			}
+		})
+	}
+}
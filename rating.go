@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Elo ratings, where a difference of 400 points means the stronger player is expected to score 10 times as much
+const (
+	initialRating = 1200
+	// Ratings change faster over the first few games so that they quickly approach the player's actual strength
+	provisionalGames   = 10
+	provisionalKFactor = 40
+	kFactor            = 20
+	maxRatingHistory   = 100
+)
+
+type rating struct {
+	Games int `json:"games"`
+	// The rating after each game, oldest first, up to `maxRatingHistory` games
+	History []float64 `json:"history"`
+}
+
+// Returns the current rating, or the given initial rating if no games have been played yet
+func (r rating) getValue(initial float64) float64 {
+	if len(r.History) == 0 {
+		return initial
+	}
+	return r.History[len(r.History)-1]
+}
+
+func (r *rating) update(initial float64, opponent float64, score float64) {
+	k := float64(kFactor)
+	if r.Games < provisionalGames {
+		k = provisionalKFactor
+	}
+
+	value := r.getValue(initial)
+	value += k * (score - getExpectedScore(value, opponent))
+
+	r.Games++
+	r.History = append(r.History, value)
+	if len(r.History) > maxRatingHistory {
+		r.History = r.History[len(r.History)-maxRatingHistory:]
+	}
+}
+
+// Returns the score a player with the first rating is expected to get against a player with the second rating, from 0
+// (certain to lose) to 1 (certain to win)
+func getExpectedScore(a float64, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+func (o gameOutcome) getScore() float64 {
+	return [...]float64{1, 0, 0.5}[o]
+}
+
+// The computer's ratings start out spread apart so that difficulties can be suggested straight away
+func (d difficulty) getInitialRating() float64 {
+	return [...]float64{1000, 1400, 1800}[d]
+}
+
+// Ratings of the computer at each difficulty, which are shared by every profile
+type computerRatings map[string]rating
+
+func (cr computerRatings) getValue(d difficulty) float64 {
+	return cr[d.String()].getValue(d.getInitialRating())
+}
+
+// Updates the ratings of a profile and of the computer after a game between them
+func updateRatings(profileRating *rating, cr computerRatings, d difficulty, outcome gameOutcome) {
+	profileValue := profileRating.getValue(initialRating)
+	computerValue := cr.getValue(d)
+
+	profileRating.update(initialRating, computerValue, outcome.getScore())
+
+	computerRating := cr[d.String()]
+	computerRating.update(d.getInitialRating(), profileValue, 1-outcome.getScore())
+	cr[d.String()] = computerRating
+}
+
+// Returns the difficulty the given rating is most evenly matched with
+func suggestDifficulty(value float64, cr computerRatings) difficulty {
+	suggested := difficulties[0]
+	for _, d := range difficulties {
+		if math.Abs(cr.getValue(d)-value) < math.Abs(cr.getValue(suggested)-value) {
+			suggested = d
+		}
+	}
+	return suggested
+}
+
+const computerRatingsFileName = "ratings.json"
+
+// Reads the computer's ratings; a missing ratings file isn't an error
+func loadComputerRatings() (computerRatings, error) {
+	cr := make(computerRatings)
+	if err := loadDataFile(computerRatingsFileName, &cr); err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+func saveComputerRatings(cr computerRatings) error {
+	return saveDataFile(computerRatingsFileName, cr)
+}
+
+// Eighths of a block, used for the top of each column of the graph
+var graphBlocks = [...]string{" ", "▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+
+// Draws the most recent ratings that fit in the given width as a column graph, labelled with the highest and lowest
+// ratings shown, or returns an empty string if there's no room for it
+func createRatingGraph(history []float64, width int, height int, t theme) string {
+	if width <= 0 {
+		return ""
+	}
+	if len(history) > width {
+		history = history[len(history)-width:]
+	}
+	if len(history) == 0 {
+		return ""
+	}
+
+	low, high := history[0], history[0]
+	for _, value := range history {
+		low = math.Min(low, value)
+		high = math.Max(high, value)
+	}
+	// Keep a flat history in the middle of the graph
+	if high-low < 1 {
+		low, high = low-1, high+1
+	}
+
+	highLabel := fmt.Sprintf("%.0f", high)
+	lowLabel := fmt.Sprintf("%.0f", low)
+	labelWidth := len(highLabel)
+	if len(lowLabel) > labelWidth {
+		labelWidth = len(lowLabel)
+	}
+
+	// Each column is filled up to its value, measured in eighths of a row, with the lowest rating filling one eighth
+	levels := make([]int, len(history))
+	for i, value := range history {
+		levels[i] = 1 + int(math.Round((value-low)/(high-low)*float64(height*len(graphBlocks[1:])-1)))
+	}
+
+	rows := make([]string, 0, height)
+	for row := height - 1; row >= 0; row-- {
+		var builder strings.Builder
+		switch row {
+		case height - 1:
+			builder.WriteString(fmt.Sprintf("%*s ┤", labelWidth, highLabel))
+		case 0:
+			builder.WriteString(fmt.Sprintf("%*s ┤", labelWidth, lowLabel))
+		default:
+			builder.WriteString(fmt.Sprintf("%*s │", labelWidth, ""))
+		}
+
+		for _, level := range levels {
+			filled := level - row*len(graphBlocks[1:])
+			if filled < 0 {
+				filled = 0
+			} else if filled >= len(graphBlocks) {
+				filled = len(graphBlocks) - 1
+			}
+			builder.WriteString(t.accent2Text.Render(graphBlocks[filled]))
+		}
+		rows = append(rows, builder.String())
+	}
+	return strings.Join(rows, "\n")
+}
+
+// Describes the current rating and how it changed in the last game
+func createRatingChangeText(r rating) string {
+	value := r.getValue(initialRating)
+	previous := float64(initialRating)
+	if len(r.History) > 1 {
+		previous = r.History[len(r.History)-2]
+	}
+	return fmt.Sprintf("Rating: %.0f (%+.0f)", value, value-previous)
+}
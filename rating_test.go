@@ -0,0 +1,151 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestUpdateRatings(t *testing.T) {
+	tests := []struct {
+		name         string
+		profile      rating
+		computer     rating
+		difficulty   difficulty
+		outcome      gameOutcome
+		wantProfile  float64
+		wantComputer float64
+	}{
+		{
+			name:         "win against an even opponent",
+			profile:      rating{Games: 20, History: []float64{1400}},
+			computer:     rating{Games: 20, History: []float64{1400}},
+			difficulty:   MediumDifficulty,
+			outcome:      Win,
+			wantProfile:  1410,
+			wantComputer: 1390,
+		},
+		{
+			name:         "loss against an even opponent",
+			profile:      rating{Games: 20, History: []float64{1400}},
+			computer:     rating{Games: 20, History: []float64{1400}},
+			difficulty:   MediumDifficulty,
+			outcome:      Loss,
+			wantProfile:  1390,
+			wantComputer: 1410,
+		},
+		{
+			name:         "draw against an even opponent",
+			profile:      rating{Games: 20, History: []float64{1400}},
+			computer:     rating{Games: 20, History: []float64{1400}},
+			difficulty:   MediumDifficulty,
+			outcome:      Draw,
+			wantProfile:  1400,
+			wantComputer: 1400,
+		},
+		{
+			name:         "provisional ratings change faster",
+			computer:     rating{Games: 20, History: []float64{1200}},
+			difficulty:   EasyDifficulty,
+			outcome:      Win,
+			wantProfile:  1220,
+			wantComputer: 1190,
+		},
+		{
+			// Expected to score 1/(1+10^(600/400)), and both ratings are provisional
+			name:         "win against a much stronger opponent",
+			difficulty:   HardDifficulty,
+			outcome:      Win,
+			wantProfile:  1200 + 40*(1-1/(1+math.Pow(10, 1.5))),
+			wantComputer: 1800 - 40*(1-1/(1+math.Pow(10, 1.5))),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profileRating := tt.profile
+			cr := computerRatings{}
+			if tt.computer.Games > 0 {
+				cr[tt.difficulty.String()] = tt.computer
+			}
+
+			updateRatings(&profileRating, cr, tt.difficulty, tt.outcome)
+
+			if got := profileRating.getValue(initialRating); math.Abs(got-tt.wantProfile) > 1e-9 {
+				t.Errorf("got profile rating %f, want %f", got, tt.wantProfile)
+			}
+			if got := cr.getValue(tt.difficulty); math.Abs(got-tt.wantComputer) > 1e-9 {
+				t.Errorf("got computer rating %f, want %f", got, tt.wantComputer)
+			}
+			if profileRating.Games != tt.profile.Games+1 || cr[tt.difficulty.String()].Games != tt.computer.Games+1 {
+				t.Errorf("got %d and %d games, want %d and %d", profileRating.Games, cr[tt.difficulty.String()].Games,
+					tt.profile.Games+1, tt.computer.Games+1)
+			}
+		})
+	}
+}
+
+func TestRatingHistoryIsLimited(t *testing.T) {
+	r := rating{Games: maxRatingHistory, History: make([]float64, maxRatingHistory)}
+	for i := range r.History {
+		r.History[i] = initialRating
+	}
+
+	r.update(initialRating, initialRating, Win.getScore())
+
+	if len(r.History) != maxRatingHistory {
+		t.Errorf("got %d ratings in the history, want %d", len(r.History), maxRatingHistory)
+	}
+	if r.getValue(initialRating) != initialRating+kFactor/2 {
+		t.Errorf("got rating %f, want %d", r.getValue(initialRating), initialRating+kFactor/2)
+	}
+}
+
+func TestSuggestDifficulty(t *testing.T) {
+	tests := []struct {
+		rating float64
+		want   difficulty
+	}{
+		{rating: 800, want: EasyDifficulty},
+		{rating: 1150, want: EasyDifficulty},
+		{rating: 1250, want: MediumDifficulty},
+		{rating: 2000, want: HardDifficulty},
+	}
+
+	for _, tt := range tests {
+		if got := suggestDifficulty(tt.rating, computerRatings{}); got != tt.want {
+			t.Errorf("suggestDifficulty(%.0f): got %s, want %s", tt.rating, got, tt.want)
+		}
+	}
+}
+
+func TestCreateRatingGraph(t *testing.T) {
+	history := []float64{1200, 1220, 1210, 1250}
+	tests := []struct {
+		name      string
+		history   []float64
+		width     int
+		wantLines int
+	}{
+		{name: "graph", history: history, width: 10, wantLines: 3},
+		{name: "history wider than the graph", history: history, width: 2, wantLines: 3},
+		{name: "no history", width: 10},
+		{name: "no room", history: history, width: 0},
+		{name: "negative width", history: history, width: -5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := createRatingGraph(tt.history, tt.width, 3, newMonochromeTheme())
+			if tt.wantLines == 0 {
+				if graph != "" {
+					t.Errorf("got graph %q, want none", graph)
+				}
+				return
+			}
+			if lines := strings.Split(graph, "\n"); len(lines) != tt.wantLines {
+				t.Errorf("got %d lines, want %d:\n%s", len(lines), tt.wantLines, graph)
+			}
+		})
+	}
+}
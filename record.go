@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"golang.org/x/exp/slices"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const transcriptMovesPerLine = 10
+
+type moveRecord struct {
+	player player
+	point  vector2d
+	pass   bool
+	// Whether the player used a hint (or had evaluation shading switched on) before making this move
+	hinted bool
+}
+
+type gameRecord struct {
+	rules       rules
+	startGrid   grid
+	startPlayer player
+	moves       []moveRecord
+	board       boardShape
+	opening     openingMode
+	// Seed used for the random parts of the start of the game, such as a random opening, if any
+	seed *int64
+}
+
+func newGameRecord(g grid, startPlayer player, r rules) gameRecord {
+	return gameRecord{
+		rules:       r,
+		startGrid:   g,
+		startPlayer: startPlayer,
+		moves:       make([]moveRecord, 0, gridWidth*gridHeight),
+	}
+}
+
+func (gr *gameRecord) addMove(p player, point vector2d, hinted bool) {
+	gr.moves = append(gr.moves, moveRecord{
+		player: p,
+		point:  point,
+		hinted: hinted,
+	})
+}
+
+func (gr *gameRecord) addPass(p player) {
+	gr.moves = append(gr.moves, moveRecord{
+		player: p,
+		pass:   true,
+	})
+}
+
+func (gr gameRecord) countHints() map[player]int {
+	hints := make(map[player]int)
+	for _, mr := range gr.moves {
+		if mr.hinted {
+			hints[mr.player]++
+		}
+	}
+	return hints
+}
+
+// Creates a record by replaying the given moves from the given state, filling in which player made each move and any
+// passes
+func replayMoves(s gameState, points []vector2d) (gameRecord, error) {
+	gr := newGameRecord(s.grid, s.player, s.rules)
+	for i, p := range points {
+		moves := s.legalMoves()
+		if len(moves) == 0 {
+			return gameRecord{}, fmt.Errorf("move %d (%s): game is already over", i+1, p)
+		}
+		if !slices.Contains(moves, p) {
+			return gameRecord{}, fmt.Errorf("move %d (%s): illegal move for %s", i+1, p, s.player)
+		}
+
+		next := s.play(p)
+		gr.addMove(s.player, p, false)
+		if next.player == s.player && len(next.legalMoves()) > 0 {
+			gr.addPass(toggleCurrentPlayer(s.player))
+		}
+		s = next
+	}
+
+	return gr, nil
+}
+
+// Returns the state before each move in the record, followed by the final state
+func (gr gameRecord) states() []gameState {
+	s := newGameState(gr.startGrid, gr.startPlayer, gr.rules)
+	states := make([]gameState, 0, len(gr.moves)+1)
+	for _, mr := range gr.moves {
+		states = append(states, s)
+		if !mr.pass {
+			s = s.play(mr.point)
+		}
+	}
+	return append(states, s)
+}
+
+// Whether the record starts from the usual starting position for its rules
+func (gr gameRecord) hasStandardStart() bool {
+	return gr.startGrid == *newGrid(gr.rules) && gr.startPlayer == DarkPlayer
+}
+
+// Whether the record starts from the position given by its rules, board and opening, rather than one set up by hand
+// (such as in the board editor)
+func (gr gameRecord) hasUsualStart() bool {
+	// Starts involving anything random are always generated from their seed
+	if gr.seed != nil {
+		return true
+	}
+
+	s := newStartState(gr.rules, gr.board, gr.opening, 0)
+	return gr.startGrid == s.grid && gr.startPlayer == s.player
+}
+
+// Reads a transcript, consisting of headers (such as the rules) followed by the moves in coordinate notation
+// Passes may be given explicitly as "pass" but are otherwise inferred; anything after a "#" is a comment
+// Games that didn't start from the usual starting position have a "Position" header giving the starting position, along
+// with "Board", "Opening" and "Seed" headers describing how it was chosen, which are used to work out the starting
+// position if there's no "Position" header
+func parseTranscript(r io.Reader) (gameRecord, error) {
+	s := gameState{rules: OthelloRules, player: DarkPlayer}
+	points := make([]vector2d, 0, gridWidth*gridHeight)
+	// Parsed once all the headers have been read, as it depends on the rules
+	var position string
+	positionLineNumber := 0
+	var seed *int64
+	board := StandardBoard
+	opening := StandardOpening
+
+	scanner := bufio.NewScanner(r)
+	readingHeaders := true
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		// Positions use "#" for blocked cells, so comments are only looked for after them
+		if key, value, ok := strings.Cut(scanner.Text(), ":"); readingHeaders && ok &&
+			strings.EqualFold(strings.TrimSpace(key), "position") {
+			position = cutComment(value)
+			positionLineNumber = lineNumber
+			continue
+		}
+
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if key, value, ok := strings.Cut(line, ":"); readingHeaders && ok {
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "rules":
+				r, err := parseRules(strings.TrimSpace(value))
+				if err != nil {
+					return gameRecord{}, fmt.Errorf("line %d: %w", lineNumber, err)
+				}
+				s.rules = r
+			case "seed":
+				n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+				if err != nil || n < 0 {
+					return gameRecord{}, fmt.Errorf("line %d: invalid seed %q", lineNumber, strings.TrimSpace(value))
+				}
+				seed = &n
+			case "board":
+				b, err := parseBoardShape(strings.TrimSpace(value))
+				if err != nil {
+					return gameRecord{}, fmt.Errorf("line %d: %w", lineNumber, err)
+				}
+				board = b
+			case "opening":
+				if !strings.EqualFold(strings.TrimSpace(value), RandomOpening.String()) {
+					return gameRecord{}, fmt.Errorf("line %d: unknown opening %q", lineNumber, strings.TrimSpace(value))
+				}
+				opening = RandomOpening
+			}
+			continue
+		}
+
+		readingHeaders = false
+		for _, token := range strings.Fields(line) {
+			if strings.EqualFold(token, "pass") {
+				continue
+			}
+
+			p, err := parsePoint(token)
+			if err != nil {
+				return gameRecord{}, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			points = append(points, p)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return gameRecord{}, err
+	}
+
+	if position != "" {
+		var err error
+		if s, err = parsePosition(position, s.rules); err != nil {
+			return gameRecord{}, fmt.Errorf("line %d: %w", positionLineNumber, err)
+		}
+	} else if seed != nil {
+		s = newStartState(s.rules, board, opening, *seed)
+	} else if needsSeed(board, opening) {
+		return gameRecord{}, errors.New("missing seed or position")
+	} else {
+		s = newStartState(s.rules, board, opening, 0)
+	}
+
+	gr, err := replayMoves(s, points)
+	if err != nil {
+		return gameRecord{}, err
+	}
+	gr.board = board
+	gr.opening = opening
+	gr.seed = seed
+	return gr, nil
+}
+
+// Reads the transcript in the given file, or from standard input if the path is "-"
+func readTranscriptFile(path string) (gameRecord, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return gameRecord{}, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	gr, err := parseTranscript(r)
+	if err != nil {
+		return gameRecord{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return gr, nil
+}
+
+// Writes the record as a transcript; if comments are given, each move is written on its own line followed by its
+// comment
+func writeTranscript(w io.Writer, gr gameRecord, comments []string) error {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("Rules: %s\n", gr.rules))
+	if gr.board != StandardBoard {
+		builder.WriteString(fmt.Sprintf("Board: %s\n", gr.board))
+	}
+	if gr.opening == RandomOpening {
+		builder.WriteString(fmt.Sprintf("Opening: %s\n", gr.opening))
+	}
+	if gr.seed != nil {
+		builder.WriteString(fmt.Sprintf("Seed: %d\n", *gr.seed))
+	}
+	if !gr.hasStandardStart() {
+		start := gameState{grid: gr.startGrid, player: gr.startPlayer, rules: gr.rules}
+		builder.WriteString(fmt.Sprintf("Position: %s\n", start))
+	}
+	builder.WriteString("\n")
+
+	for i, mr := range gr.moves {
+		token := "pass"
+		if !mr.pass {
+			token = mr.point.String()
+		}
+
+		if comments != nil {
+			builder.WriteString(fmt.Sprintf("%-4s # %d. %s\n", token, i+1, comments[i]))
+		} else if i%transcriptMovesPerLine == transcriptMovesPerLine-1 || i == len(gr.moves)-1 {
+			builder.WriteString(token + "\n")
+		} else {
+			builder.WriteString(token + " ")
+		}
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Returns a record of the given number of moves from the given state, always playing the last legal move
+func playTestGame(t *testing.T, start gameState, moves int) gameRecord {
+	t.Helper()
+	s := start
+	points := make([]vector2d, 0, moves)
+	for i := 0; i < moves && len(s.legalMoves()) > 0; i++ {
+		legalMoves := s.legalMoves()
+		p := legalMoves[len(legalMoves)-1]
+		points = append(points, p)
+		s = s.play(p)
+	}
+
+	gr, err := replayMoves(start, points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gr
+}
+
+func TestTranscriptRoundTrip(t *testing.T) {
+	seed := int64(42)
+	tests := []struct {
+		name    string
+		start   gameState
+		moves   int
+		board   boardShape
+		opening openingMode
+		seed    *int64
+	}{
+		{name: "Othello", start: newStartState(OthelloRules, StandardBoard, StandardOpening, 0), moves: 60},
+		{name: "Reversi", start: newStartState(ReversiRules, StandardBoard, StandardOpening, 0), moves: 60},
+		{name: "Anti-Reversi", start: newStartState(AntiReversiRules, StandardBoard, StandardOpening, 0), moves: 60},
+		{name: "Torus", start: newStartState(TorusRules, StandardBoard, StandardOpening, 0), moves: 60},
+		{
+			name:  "octagon board",
+			start: newStartState(OthelloRules, OctagonBoard, StandardOpening, 0),
+			moves: 20,
+			board: OctagonBoard,
+		},
+		{
+			name:  "random holes",
+			start: newStartState(OthelloRules, RandomHolesBoard, StandardOpening, seed),
+			moves: 20,
+			board: RandomHolesBoard,
+			seed:  &seed,
+		},
+		{
+			name:    "random opening",
+			start:   newStartState(ReversiRules, StandardBoard, RandomOpening, seed),
+			moves:   20,
+			opening: RandomOpening,
+			seed:    &seed,
+		},
+		{
+			name:  "custom position",
+			start: parseTestPosition(t, "...................X.......XX......XO........................... O", OthelloRules),
+			moves: 20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gr := playTestGame(t, tt.start, tt.moves)
+			gr.board = tt.board
+			gr.opening = tt.opening
+			gr.seed = tt.seed
+
+			for _, comments := range [][]string{nil, make([]string, len(gr.moves))} {
+				var builder strings.Builder
+				if err := writeTranscript(&builder, gr, comments); err != nil {
+					t.Fatal(err)
+				}
+
+				got, err := parseTranscript(strings.NewReader(builder.String()))
+				if err != nil {
+					t.Fatalf("%v\n%s", err, builder.String())
+				}
+				if !reflect.DeepEqual(got, gr) {
+					t.Errorf("transcript read back differently:\n%s", builder.String())
+				}
+			}
+		})
+	}
+}
+
+func TestParseTranscriptErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		transcript string
+	}{
+		{name: "unknown rules", transcript: "Rules: Chess\n\nf5"},
+		{name: "unknown board", transcript: "Board: Hexagon\n\nf5"},
+		{name: "invalid seed", transcript: "Opening: Random\nSeed: -1\n\nf5"},
+		{name: "missing seed", transcript: "Board: Random holes\n\nf5"},
+		{name: "invalid position", transcript: "Position: ...X\n\nf5"},
+		{name: "invalid point", transcript: "Rules: Othello\n\nf5 z9"},
+		{name: "illegal move", transcript: "Rules: Othello\n\na1"},
+		{name: "move after the end of the game", transcript: "Rules: Othello\n" +
+			"Position: XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX. X\n\nh8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseTranscript(strings.NewReader(tt.transcript)); err == nil {
+				t.Errorf("got no error for %q", tt.transcript)
+			}
+		})
+	}
+}
+
+func TestHasUsualStart(t *testing.T) {
+	seed := int64(42)
+	tests := []struct {
+		name  string
+		start gameState
+		board boardShape
+		seed  *int64
+		want  bool
+	}{
+		{name: "Othello", start: newStartState(OthelloRules, StandardBoard, StandardOpening, 0), want: true},
+		{name: "Reversi", start: newStartState(ReversiRules, StandardBoard, StandardOpening, 0), want: true},
+		{
+			name:  "octagon board",
+			start: newStartState(OthelloRules, OctagonBoard, StandardOpening, 0),
+			board: OctagonBoard,
+			want:  true,
+		},
+		{
+			name:  "random holes",
+			start: newStartState(OthelloRules, RandomHolesBoard, StandardOpening, seed),
+			board: RandomHolesBoard,
+			seed:  &seed,
+			want:  true,
+		},
+		{
+			name:  "custom position",
+			start: parseTestPosition(t, "...................X.......XX......XO........................... O", OthelloRules),
+		},
+		{
+			name:  "Othello start on an octagon board",
+			start: newStartState(OthelloRules, StandardBoard, StandardOpening, 0),
+			board: OctagonBoard,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gr := playTestGame(t, tt.start, 0)
+			gr.board = tt.board
+			gr.seed = tt.seed
+			if got := gr.hasUsualStart(); got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
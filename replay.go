@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize/english"
+	"io"
+	"os"
+	"time"
+)
+
+var replaySpeeds = []time.Duration{
+	2 * time.Second,
+	time.Second,
+	500 * time.Millisecond,
+	250 * time.Millisecond,
+}
+
+const defaultReplaySpeed = 1
+
+type replay struct {
+	states   []gameState
+	index    int
+	autoPlay bool
+	speed    int
+	// Used to discard ticks after auto-play has been stopped or restarted
+	tickID int
+}
+
+type replayTickMsg int
+
+func newReplay(gr gameRecord) replay {
+	return replay{
+		states: gr.states(),
+		speed:  defaultReplaySpeed,
+	}
+}
+
+func (r replay) isAtEnd() bool {
+	return r.index == len(r.states)-1
+}
+
+func (r replay) tick() tea.Cmd {
+	id := r.tickID
+	return tea.Tick(replaySpeeds[r.speed], func(time.Time) tea.Msg {
+		return replayTickMsg(id)
+	})
+}
+
+func (r *replay) toggleAutoPlay() tea.Cmd {
+	r.autoPlay = !r.autoPlay
+	r.tickID++
+	if !r.autoPlay {
+		return nil
+	}
+
+	// Start again from the beginning if already at the end
+	if r.isAtEnd() {
+		r.index = 0
+	}
+	return r.tick()
+}
+
+func (r *replay) stopAutoPlay() {
+	r.autoPlay = false
+	r.tickID++
+}
+
+// Returns the disks flipped by the move leading to the current position, by comparing it with the previous position
+func (r replay) getDisksFlipped(gr gameRecord) []vector2d {
+	disksFlipped := make([]vector2d, 0)
+	if r.index == 0 || gr.moves[r.index-1].pass {
+		return disksFlipped
+	}
+
+	before, after := r.states[r.index-1].grid, r.states[r.index].grid
+	for i, row := range after {
+		for j, cell := range row {
+			if before[i][j] != Blank && before[i][j] != cell {
+				disksFlipped = append(disksFlipped, vector2d{j, i})
+			}
+		}
+	}
+	return disksFlipped
+}
+
+// Creates a model for replaying the given game; once the replay is exited, the game over view is shown for the game
+func createReplayModel(gr gameRecord, s settings) model {
+	s.rules = gr.rules
+	s.playerMode = TwoPlayer
+	m := createModelFromState(s, gr.states()[len(gr.moves)])
+	m.record = gr
+	m.replay = newReplay(gr)
+	m.view = ReplayView
+
+	return m
+}
+
+func runReplay(args []string, s settings, _ io.Writer) error {
+	flags := newFlagSet("replay", "FILE")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageError(flags)
+	}
+
+	gr, err := readTranscriptFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if len(gr.moves) == 0 {
+		return fmt.Errorf("%s: no moves to replay", flags.Arg(0))
+	}
+
+	return runProgram(createReplayModel(gr, s))
+}
+
+type gameSavedMsg struct {
+	path string
+	err  error
+}
+
+// Saves the game's transcript to a new file in the current directory
+func saveGameCmd(gr gameRecord) tea.Cmd {
+	return func() tea.Msg {
+		path := fmt.Sprintf("reversi-%s.txt", time.Now().Format("20060102-150405"))
+
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return gameSavedMsg{err: err}
+		}
+
+		if err := writeTranscript(f, gr, nil); err != nil {
+			f.Close()
+			return gameSavedMsg{err: err}
+		}
+
+		return gameSavedMsg{path: path, err: f.Close()}
+	}
+}
+
+func createReplayView(m model, maxWidth int) string {
+	t := m.getTheme()
+	textStrings := make([]string, 0, 10)
+
+	if m.replay.index == 0 {
+		textStrings = append(textStrings, t.accent1Text.Render("Replay (start of game)"), "", "")
+	} else {
+		mr := m.record.moves[m.replay.index-1]
+		textStrings = append(textStrings,
+			t.accent1Text.Render(fmt.Sprintf("Replay (move %d of %d)", m.replay.index, len(m.record.moves))),
+			"")
+
+		if mr.pass {
+			textStrings = append(textStrings, fmt.Sprintf("%s (%s) passed", mr.player, mr.player.toSymbol()))
+		} else {
+			disksFlipped := m.replay.getDisksFlipped(m.record)
+			textStrings = append(textStrings, fmt.Sprintf("%s (%s) played %s, flipping %s", mr.player,
+				mr.player.toSymbol(), mr.point, english.Plural(len(disksFlipped), "disk", "")))
+		}
+	}
+
+	s := m.replay.states[m.replay.index]
+	textStrings = append(textStrings, createGameStatusText(computeScores(s.grid), s.rules, s.supply), "")
+
+	if m.replay.autoPlay {
+		textStrings = append(textStrings, fmt.Sprintf("Auto-play: on (%s per move)", replaySpeeds[m.replay.speed]))
+	} else {
+		textStrings = append(textStrings, fmt.Sprintf("Auto-play: off (%s per move)", replaySpeeds[m.replay.speed]))
+	}
+
+	textStrings = append(textStrings, "",
+		t.secondaryText.Render(createReplayHelp(m.keys)))
+
+	return lipgloss.NewStyle().
+		Width(maxWidth).
+		Render(lipgloss.JoinVertical(lipgloss.Left, textStrings...))
+}
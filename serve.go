@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/exp/slices"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+type movesResponse struct {
+	Player string   `json:"player"`
+	Moves  []string `json:"moves"`
+}
+
+type bestMoveResponse struct {
+	Move string `json:"move"`
+	// Only given if the computer searches ahead at the requested difficulty
+	Score *int `json:"score,omitempty"`
+}
+
+type playResponse struct {
+	Position string   `json:"position"`
+	Flipped  []string `json:"flipped"`
+	GameOver bool     `json:"gameOver"`
+}
+
+type analysedMoveJSON struct {
+	Player    string `json:"player"`
+	Move      string `json:"move"`
+	Before    int    `json:"before"`
+	After     int    `json:"after"`
+	Best      string `json:"best,omitempty"`
+	Judgement string `json:"judgement,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, apiError{Error: err.Error()})
+}
+
+func formatPointList(points []vector2d) []string {
+	pointStrings := make([]string, 0, len(points))
+	for _, p := range points {
+		pointStrings = append(pointStrings, p.String())
+	}
+	return pointStrings
+}
+
+// Reads the position from the "position" query parameter, played by the rules in the "rules" parameter if given
+func getRequestState(r *http.Request) (gameState, error) {
+	rulesName := r.URL.Query().Get("rules")
+	if rulesName == "" {
+		rulesName = OthelloRules.String()
+	}
+
+	rules, err := parseRules(rulesName)
+	if err != nil {
+		return gameState{}, err
+	}
+
+	position := r.URL.Query().Get("position")
+	if position == "" {
+		return gameState{}, errors.New("missing position")
+	}
+	return parsePosition(position, rules)
+}
+
+func handleMoves(w http.ResponseWriter, r *http.Request) {
+	state, err := getRequestState(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, movesResponse{
+		Player: state.player.toSymbol(),
+		Moves:  formatPointList(state.legalMoves()),
+	})
+}
+
+func handleBestMove(w http.ResponseWriter, r *http.Request) {
+	state, err := getRequestState(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	d := HardDifficulty
+	if name := r.URL.Query().Get("difficulty"); name != "" {
+		d, err = parseOption(difficulties, name)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	if len(state.legalMoves()) == 0 {
+		writeAPIError(w, http.StatusUnprocessableEntity, fmt.Errorf("no legal moves for %s", state.player))
+		return
+	}
+
+	e, ok := d.getEngine()
+	if !ok {
+		writeJSON(w, http.StatusOK, bestMoveResponse{Move: d.chooseMove(state).String()})
+		return
+	}
+
+	p, score := e.bestMove(state)
+	writeJSON(w, http.StatusOK, bestMoveResponse{Move: p.String(), Score: &score})
+}
+
+func handlePlay(w http.ResponseWriter, r *http.Request) {
+	state, err := getRequestState(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	p, err := parsePoint(r.URL.Query().Get("move"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !slices.Contains(state.legalMoves(), p) {
+		writeAPIError(w, http.StatusUnprocessableEntity, fmt.Errorf("illegal move %s for %s", p, state.player))
+		return
+	}
+
+	next := state.play(p)
+	writeJSON(w, http.StatusOK, playResponse{
+		Position: next.String(),
+		Flipped:  formatPointList(getPointsToFlip(state.grid, p, state.player, state.rules)),
+		GameOver: len(next.legalMoves()) == 0,
+	})
+}
+
+// Analyses the transcript in the request body
+func handleAnalyze(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, errors.New("transcripts must be sent using POST"))
+		return
+	}
+
+	gr, err := parseTranscript(io.LimitReader(r.Body, 1<<16))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	analyses := analyseGame(gr, analysisEngine)
+	moves := make([]analysedMoveJSON, 0, len(analyses))
+	for _, a := range analyses {
+		if a.move.pass {
+			moves = append(moves, analysedMoveJSON{Player: a.move.player.toSymbol(), Move: "pass"})
+			continue
+		}
+
+		mj := analysedMoveJSON{
+			Player:    a.move.player.toSymbol(),
+			Move:      a.move.point.String(),
+			Before:    a.before,
+			After:     a.after,
+			Judgement: strings.ToLower(a.judgement.String()),
+		}
+		if a.after < a.before {
+			mj.Best = a.best.String()
+		}
+		moves = append(moves, mj)
+	}
+
+	writeJSON(w, http.StatusOK, moves)
+}
+
+func runServe(args []string, _ settings, w io.Writer) error {
+	flags := newFlagSet("serve", "[flags]")
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return usageError(flags)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/moves", handleMoves)
+	mux.HandleFunc("/best-move", handleBestMove)
+	mux.HandleFunc("/play", handlePlay)
+	mux.HandleFunc("/analyze", handleAnalyze)
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fmt.Fprintf(w, "Listening on %s\n", *addr)
+	return server.ListenAndServe()
+}
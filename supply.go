@@ -0,0 +1,41 @@
+package main
+
+import "fmt"
+
+// Number of disks each player has under Reversi rules; under Othello rules, players never run out
+const diskSupplySize = gridWidth * gridHeight / 2
+
+// Number of disks each player has left to place, indexed by player
+type diskSupply [2]int
+
+// Returns the supply for the given position, assuming the players have taken turns placing the disks on the board,
+// starting from an empty board
+// If the number of disks is odd, the player to move is assumed to have placed one fewer than their opponent
+func newDiskSupply(g grid, toMove player) diskSupply {
+	placed := len(getNonBlankPoints(g))
+
+	var ds diskSupply
+	ds[toMove] = diskSupplySize - placed/2
+	ds[toggleCurrentPlayer(toMove)] = diskSupplySize - (placed+1)/2
+	return ds
+}
+
+func (r rules) hasDiskSupply() bool {
+	return r == ReversiRules
+}
+
+// Whether the given player has no disks left to place
+func (ds diskSupply) isExhausted(p player, r rules) bool {
+	return r.hasDiskSupply() && ds[p] <= 0
+}
+
+// Records a disk being placed by the given player
+func (ds *diskSupply) use(p player, r rules) {
+	if r.hasDiskSupply() {
+		ds[p]--
+	}
+}
+
+func createDiskSupplyText(ds diskSupply) string {
+	return fmt.Sprintf("Disks left: %s: %d; %s: %d", DarkPlayer, ds[DarkPlayer], LightPlayer, ds[LightPlayer])
+}
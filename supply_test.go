@@ -0,0 +1,99 @@
+package main
+
+import "testing"
+
+func TestNewDiskSupply(t *testing.T) {
+	tests := []struct {
+		name     string
+		position string
+		want     diskSupply
+	}{
+		{
+			name:     "empty board",
+			position: "................................................................ X",
+			want:     diskSupply{32, 32},
+		},
+		{
+			name:     "Othello start",
+			position: "...........................OX......XO........................... X",
+			want:     diskSupply{30, 30},
+		},
+		{
+			name:     "odd number of disks",
+			position: "...................X.......XX......XO........................... O",
+			want:     diskSupply{29, 30},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := parseTestPosition(t, tt.position, ReversiRules)
+			if s.supply != tt.want {
+				t.Errorf("got %v, want %v", s.supply, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTurnOutcome(t *testing.T) {
+	const start = "...........................OX......XO........................... X"
+	// Dark can't move, but light can
+	const darkStuck = "XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXOXXXXXX. X"
+	// Light has no disks left, so neither player can move
+	const lightGone = "XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX. X"
+
+	tests := []struct {
+		name     string
+		position string
+		rules    rules
+		supply   *diskSupply
+		want     turnOutcome
+	}{
+		{name: "Othello start", position: start, rules: OthelloRules, want: PlayerMoves},
+		{name: "Reversi moves", position: start, rules: ReversiRules, want: PlayerMoves},
+		{name: "Othello pass", position: darkStuck, rules: OthelloRules, want: PlayerPasses},
+		{name: "Reversi ends when player can't move", position: darkStuck, rules: ReversiRules, want: GameOver},
+		{name: "neither player can move", position: lightGone, rules: OthelloRules, want: GameOver},
+		{
+			name:     "out of disks",
+			position: start,
+			rules:    ReversiRules,
+			supply:   &diskSupply{0, 2},
+			want:     PlayerPasses,
+		},
+		{
+			name:     "out of disks and opponent can't move",
+			position: "XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX.. O",
+			rules:    ReversiRules,
+			supply:   &diskSupply{2, 0},
+			want:     GameOver,
+		},
+		{
+			name:     "both out of disks",
+			position: start,
+			rules:    ReversiRules,
+			supply:   &diskSupply{0, 0},
+			want:     GameOver,
+		},
+		{
+			name:     "supply ignored under Othello rules",
+			position: start,
+			rules:    OthelloRules,
+			supply:   &diskSupply{0, 0},
+			want:     PlayerMoves,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := parseTestPosition(t, tt.position, tt.rules)
+			if tt.supply != nil {
+				s.supply = *tt.supply
+			}
+
+			if got := getTurnOutcome(s.grid, s.player, s.rules, s.supply); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
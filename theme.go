@@ -0,0 +1,344 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/charmbracelet/lipgloss"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type themeName string
+
+func (t themeName) String() string {
+	return string(t)
+}
+
+// The styles used to render the grid and text
+type theme struct {
+	name                   themeName
+	borderColor            lipgloss.TerminalColor
+	board                  lipgloss.Style
+	darkPlayer             lipgloss.Style
+	lightPlayer            lipgloss.Style
+	selectedDarkPlayer     lipgloss.Style
+	selectedLightPlayer    lipgloss.Style
+	selectedBlank          lipgloss.Style
+	lastMoveDarkPlayer     lipgloss.Style
+	lastMoveLightPlayer    lipgloss.Style
+	highlightedDarkPlayer  lipgloss.Style
+	highlightedLightPlayer lipgloss.Style
+	availablePoint         lipgloss.Style
+	blocked                lipgloss.Style
+	// Ordered from worst to best evaluation
+	evaluation    []lipgloss.Style
+	bestMove      lipgloss.Style
+	secondaryText lipgloss.Style
+	accent1Text   lipgloss.Style
+	accent2Text   lipgloss.Style
+	successText   lipgloss.Style
+	errorText     lipgloss.Style
+}
+
+// The colours making up a theme, as stored in a theme file
+// Colours are hex codes or ANSI colour numbers; an empty colour leaves the terminal's default colour
+type palette struct {
+	Name           string   `json:"name"`
+	Accent1        string   `json:"accent1"`
+	Accent2        string   `json:"accent2"`
+	DarkDisk       string   `json:"darkDisk"`
+	LightDisk      string   `json:"lightDisk"`
+	Board          string   `json:"board"`
+	AvailablePoint string   `json:"availablePoint"`
+	Highlighted    string   `json:"highlighted"`
+	Evaluation     []string `json:"evaluation"`
+	BestMove       string   `json:"bestMove"`
+	SecondaryText  string   `json:"secondaryText"`
+	SuccessText    string   `json:"successText"`
+	ErrorText      string   `json:"errorText"`
+}
+
+var defaultPalette = palette{
+	Name:           "Default",
+	Accent1:        "63",
+	Accent2:        "105",
+	DarkDisk:       "#000000",
+	LightDisk:      "#ffffff",
+	AvailablePoint: "#404040",
+	Highlighted:    "#666666",
+	Evaluation:     []string{"#2a2a2a", "#404040", "#5c5c5c", "#7a7a7a"},
+	BestMove:       "#006600",
+	SecondaryText:  "241",
+	SuccessText:    "#00cc00",
+	ErrorText:      "#cc0000",
+}
+
+var classicPalette = palette{
+	Name:           "Classic",
+	Accent1:        "34",
+	Accent2:        "#ffd700",
+	DarkDisk:       "#000000",
+	LightDisk:      "#ffffff",
+	Board:          "#0b6623",
+	AvailablePoint: "#2e8b57",
+	Highlighted:    "#666666",
+	Evaluation:     []string{"#0e4d1c", "#1f7a34", "#3a9d4f", "#5cbf6d"},
+	BestMove:       "#b8860b",
+	SecondaryText:  "241",
+	SuccessText:    "#00cc00",
+	ErrorText:      "#cc0000",
+}
+
+var highContrastPalette = palette{
+	Name:           "High contrast",
+	Accent1:        "#ffff00",
+	Accent2:        "#00ffff",
+	DarkDisk:       "#000000",
+	LightDisk:      "#ffffff",
+	AvailablePoint: "#808080",
+	Highlighted:    "#808080",
+	Evaluation:     []string{"#303030", "#606060", "#909090", "#c0c0c0"},
+	BestMove:       "#00ff00",
+	SecondaryText:  "#c0c0c0",
+	SuccessText:    "#00ff00",
+	ErrorText:      "#ff5555",
+}
+
+var lightPalette = palette{
+	Name:           "Light",
+	Accent1:        "#3b2fc9",
+	Accent2:        "#7b61ff",
+	DarkDisk:       "#000000",
+	LightDisk:      "#dddddd",
+	AvailablePoint: "#c8c8c8",
+	Highlighted:    "#999999",
+	Evaluation:     []string{"#e6e6e6", "#cccccc", "#b3b3b3", "#999999"},
+	BestMove:       "#7fd17f",
+	SecondaryText:  "#777777",
+	SuccessText:    "#008800",
+	ErrorText:      "#cc0000",
+}
+
+func getColor(c string) lipgloss.TerminalColor {
+	if c == "" {
+		return lipgloss.NoColor{}
+	}
+	return lipgloss.Color(c)
+}
+
+func newTheme(p palette) theme {
+	accent1 := getColor(p.Accent1)
+	accent2 := getColor(p.Accent2)
+	darkDisk := getColor(p.DarkDisk)
+	lightDisk := getColor(p.LightDisk)
+	highlighted := getColor(p.Highlighted)
+
+	darkPlayer := lipgloss.NewStyle().
+		Foreground(lightDisk).
+		Background(darkDisk)
+	lightPlayer := lipgloss.NewStyle().
+		Foreground(darkDisk).
+		Background(lightDisk)
+
+	evaluation := make([]lipgloss.Style, 0, len(p.Evaluation))
+	for _, c := range p.Evaluation {
+		evaluation = append(evaluation, lipgloss.NewStyle().Background(getColor(c)))
+	}
+
+	return theme{
+		name:        themeName(p.Name),
+		borderColor: accent1,
+		board: lipgloss.NewStyle().
+			Background(getColor(p.Board)),
+		darkPlayer:  darkPlayer,
+		lightPlayer: lightPlayer,
+		selectedDarkPlayer: lipgloss.NewStyle().
+			Underline(true).
+			Bold(true).
+			Foreground(accent2).
+			Background(darkDisk),
+		selectedLightPlayer: lipgloss.NewStyle().
+			Foreground(darkDisk).
+			Background(accent2),
+		selectedBlank: lipgloss.NewStyle().
+			Background(accent2),
+		lastMoveDarkPlayer: darkPlayer.Copy().
+			Underline(true).
+			Bold(true),
+		lastMoveLightPlayer: lightPlayer.Copy().
+			Underline(true).
+			Bold(true),
+		highlightedDarkPlayer: lipgloss.NewStyle().
+			Foreground(highlighted).
+			Background(darkDisk),
+		highlightedLightPlayer: lipgloss.NewStyle().
+			Foreground(darkDisk).
+			Background(highlighted),
+		availablePoint: lipgloss.NewStyle().
+			Background(getColor(p.AvailablePoint)),
+		blocked: lipgloss.NewStyle().
+			Foreground(getColor(p.SecondaryText)).
+			Background(getColor(p.Board)),
+		evaluation: evaluation,
+		bestMove: lipgloss.NewStyle().
+			Background(getColor(p.BestMove)),
+		secondaryText: lipgloss.NewStyle().
+			Foreground(getColor(p.SecondaryText)),
+		accent1Text: lipgloss.NewStyle().
+			Foreground(accent1).
+			Bold(true),
+		accent2Text: lipgloss.NewStyle().
+			Foreground(accent2),
+		successText: lipgloss.NewStyle().
+			Foreground(getColor(p.SuccessText)),
+		errorText: lipgloss.NewStyle().
+			Foreground(getColor(p.ErrorText)),
+	}
+}
+
+// Uses text attributes rather than colours, for terminals without colour support
+func newMonochromeTheme() theme {
+	underline := lipgloss.NewStyle().Underline(true)
+	reverse := lipgloss.NewStyle().Reverse(true)
+	faint := lipgloss.NewStyle().Faint(true)
+	bold := lipgloss.NewStyle().Bold(true)
+
+	return theme{
+		name:                   "Monochrome",
+		borderColor:            lipgloss.NoColor{},
+		board:                  lipgloss.NewStyle(),
+		darkPlayer:             lipgloss.NewStyle(),
+		lightPlayer:            lipgloss.NewStyle(),
+		selectedDarkPlayer:     reverse,
+		selectedLightPlayer:    reverse,
+		selectedBlank:          reverse,
+		lastMoveDarkPlayer:     underline.Copy().Bold(true),
+		lastMoveLightPlayer:    underline.Copy().Bold(true),
+		highlightedDarkPlayer:  faint,
+		highlightedLightPlayer: faint,
+		availablePoint:         underline,
+		blocked:                faint,
+		evaluation:             []lipgloss.Style{underline},
+		bestMove:               underline,
+		secondaryText:          faint,
+		accent1Text:            bold,
+		accent2Text:            bold,
+		successText:            lipgloss.NewStyle(),
+		errorText:              bold,
+	}
+}
+
+// Built-in themes followed by any loaded from the user's theme directory
+var themes = []theme{
+	newTheme(defaultPalette),
+	newTheme(classicPalette),
+	newTheme(highContrastPalette),
+	newTheme(lightPalette),
+	newMonochromeTheme(),
+}
+
+func getThemeNames() []themeName {
+	names := make([]themeName, 0, len(themes))
+	for _, t := range themes {
+		names = append(names, t.name)
+	}
+	return names
+}
+
+// Returns the theme with the given name, falling back to the default theme if there isn't one, e.g. if a user theme
+// has been removed
+func getTheme(name themeName) theme {
+	for _, t := range themes {
+		if t.name == name {
+			return t
+		}
+	}
+	return themes[0]
+}
+
+func (s settings) getTheme() theme {
+	return getTheme(s.theme)
+}
+
+func toggleTheme(name themeName) themeName {
+	names := getThemeNames()
+	for i, n := range names {
+		if n == name {
+			return names[(i+1)%len(names)]
+		}
+	}
+	return names[0]
+}
+
+const themeDirectoryName = "themes"
+
+// Parses a theme file; any colours missing from the file are taken from the default theme
+func parseTheme(data []byte) (theme, error) {
+	p := defaultPalette
+	p.Name = ""
+	if err := json.Unmarshal(data, &p); err != nil {
+		return theme{}, err
+	}
+
+	if strings.TrimSpace(p.Name) == "" {
+		return theme{}, errors.New("theme has no name")
+	}
+	if len(p.Evaluation) == 0 {
+		return theme{}, errors.New("theme has no evaluation colours")
+	}
+
+	return newTheme(p), nil
+}
+
+// Adds the themes in the user's theme directory to the list of themes
+// Themes that can't be loaded are skipped, with the errors returned together
+func loadUserThemes() error {
+	dir, err := getDataPath(themeDirectoryName)
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		t, err := parseTheme(data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+
+		// A user theme replaces a built-in theme with the same name
+		replaced := false
+		for i := range themes {
+			if themes[i].name == t.name {
+				themes[i] = t
+				replaced = true
+			}
+		}
+		if !replaced {
+			themes = append(themes, t)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/exp/slices"
+)
+
+// A step of the tutorial, which either just explains something about a position, or asks the learner to make a move
+type tutorialStep struct {
+	title string
+	text  []string
+	state gameState
+	// Whether the given move is what the step asks for; nil for steps that just explain something
+	isCorrect func(s gameState, p vector2d) bool
+	// Shown once the learner has made the move the step asks for
+	success string
+	// Shown if the learner makes a legal move that isn't what the step asks for
+	hint string
+}
+
+func mustParsePosition(s string, r rules) gameState {
+	state, err := parsePosition(s, r)
+	if err != nil {
+		panic(fmt.Sprintf("invalid tutorial position: %v", err))
+	}
+	return state
+}
+
+func isAnyMove(gameState, vector2d) bool {
+	return true
+}
+
+func isCorner(p vector2d) bool {
+	return (p.x == 0 || p.x == gridWidth-1) && (p.y == 0 || p.y == gridHeight-1)
+}
+
+// Returns the number of legal moves the opponent would have after the given move
+func getOpponentMobility(s gameState, p vector2d) int {
+	next := s.play(p)
+	return len(getAvailablePoints(next.grid, toggleCurrentPlayer(s.player), next.rules))
+}
+
+var tutorialSteps = []tutorialStep{
+	{
+		title: "Placing disks",
+		text: []string{
+			"Players take turns to place a disk of their colour, with Dark going first.",
+			"A disk must be placed so that it traps one or more of the opponent's disks in a straight line between it and another of the player's disks.",
+			"Under Othello rules, the game starts with four disks in the centre. The places Dark can move are marked.",
+		},
+		state:     gameState{grid: *newGrid(OthelloRules), player: DarkPlayer, rules: OthelloRules},
+		isCorrect: isAnyMove,
+		success:   "The trapped disk has been flipped over to Dark.",
+	},
+	{
+		title: "Flipping in every direction",
+		text: []string{
+			"Disks are trapped along every line from the new disk: horizontally, vertically and diagonally.",
+			"Find the move that flips disks in all eight directions at once.",
+		},
+		state: mustParsePosition("........ .X.X.X.. ..OOO... .XO.OX.. ..OOO... .X.X.X.. ........ ........ X",
+			OthelloRules),
+		isCorrect: func(s gameState, p vector2d) bool {
+			return len(getPointsToFlip(s.grid, p, s.player, s.rules)) == 8
+		},
+		success: "Each of the eight lines had a light disk trapped by a dark disk, so all eight were flipped.",
+		hint:    "Look for the empty cell surrounded by light disks, with dark disks beyond them.",
+	},
+	{
+		title: "Passing",
+		text: []string{
+			"If a player can't trap any disks, they have to pass and their opponent moves again.",
+			"The game ends once neither player can move, and whoever has the most disks wins.",
+			"Find the move that leaves Light with no moves, so that they have to pass.",
+		},
+		state: mustParsePosition(".........O.....O..O...OO...OOOOO.OOOOXOO...OOOOX..OO..O..O...... X", OthelloRules),
+		isCorrect: func(s gameState, p vector2d) bool {
+			return getOpponentMobility(s, p) == 0
+		},
+		success: "Light can't move, so Dark gets to move again.",
+		hint:    "Light can still move after that. Which move would take away all of Light's moves?",
+	},
+	{
+		title: "Reversi rules",
+		text: []string{
+			"Under Reversi rules, the board starts out empty and the first four disks must be placed in the four centre cells.",
+			"After that, the game is played in the same way as Othello, except that the game ends as soon as a player can't move instead of them passing.",
+			"Place a disk in one of the centre cells.",
+		},
+		state:     newGameState(*newGrid(ReversiRules), DarkPlayer, ReversiRules),
+		isCorrect: isAnyMove,
+		success:   "Players take turns to fill the centre cells, without flipping any disks, before play continues as normal.",
+	},
+	{
+		title: "Corners",
+		text: []string{
+			"Flipping the most disks isn't always best, as disks can be flipped back later.",
+			"Disks in the corners can never be flipped, as there's no way to trap them, and they help to make the disks next to them safe too.",
+			"Take the corner.",
+		},
+		state: mustParsePosition("..........OOOOO...OX.O....OXOX.....OXX....OOOO....X..XO......... X", OthelloRules),
+		isCorrect: func(s gameState, p vector2d) bool {
+			return isCorner(p)
+		},
+		success: "That corner disk is Dark's for the rest of the game.",
+		hint:    "That move flips disks, but they could be flipped back. Which move can never be undone?",
+	},
+	{
+		title: "Mobility",
+		text: []string{
+			"Having more moves to choose from than your opponent is an advantage, as they may be forced into a bad move, such as one that gives away a corner.",
+			"Placing the cells next to empty corners often gives the corner away, for example.",
+			"Find the move that leaves Light with the fewest moves.",
+		},
+		state: mustParsePosition(".............O.....XOX....XOX.....OXXX......OXX....OOOXO...XO..X X", OthelloRules),
+		isCorrect: func(s gameState, p vector2d) bool {
+			for _, other := range s.legalMoves() {
+				if getOpponentMobility(s, other) < getOpponentMobility(s, p) {
+					return false
+				}
+			}
+			return true
+		},
+		success: "Light has fewer moves to choose from, even though the move flipped fewer disks.",
+		hint:    "That move leaves Light with more moves than another one would.",
+	},
+	{
+		title: "Ready to play",
+		text: []string{
+			"That's all you need to know to play.",
+			"During a game, you can ask for a hint or shade the available moves by how good the computer thinks they are; press ? to see the keys for each screen.",
+			"Try the puzzles to practise finding the best move.",
+		},
+		state: gameState{grid: *newGrid(OthelloRules), player: DarkPlayer, rules: OthelloRules},
+	},
+}
+
+type tutorial struct {
+	index int
+	// The correct move made in the current step, if any
+	move *vector2d
+	// Whether the last move made was legal but not what the step asked for
+	wrongMove bool
+}
+
+func (t tutorial) getStep() tutorialStep {
+	return tutorialSteps[t.index]
+}
+
+// Returns the grid after the correct move has been made, or the step's position if it hasn't yet
+func (t tutorial) getGrid() grid {
+	if t.move == nil {
+		return t.getStep().state.grid
+	}
+	return t.getStep().state.play(*t.move).grid
+}
+
+// Whether the learner can place a disk, as opposed to pressing a key to continue
+func (t tutorial) isAwaitingMove() bool {
+	return t.getStep().isCorrect != nil && t.move == nil
+}
+
+func startTutorial(m *model) {
+	m.view = TutorialView
+	showTutorialStep(m, 0)
+}
+
+// Sets up the board for the given step
+func showTutorialStep(m *model, index int) {
+	m.tutorial = tutorial{index: index}
+	s := m.tutorial.getStep().state
+	m.grid = s.grid
+	m.currentPlayer = s.player
+	m.supply = s.supply
+	m.availablePoints = s.legalMoves()
+	m.record = newGameRecord(s.grid, s.player, s.rules)
+	m.disksFlipped = nil
+}
+
+// Moves on to the next step, returning to the title screen after the last one
+func advanceTutorial(m *model) {
+	if m.tutorial.index == len(tutorialSteps)-1 {
+		*m = resetModel(*m)
+		return
+	}
+
+	showTutorialStep(m, m.tutorial.index+1)
+}
+
+// Checks the move at the selected point, making it if it's what the step asks for
+func makeTutorialMove(m *model) {
+	step := m.tutorial.getStep()
+	if !m.tutorial.isAwaitingMove() || !slices.Contains(m.availablePoints, m.selectedPoint) {
+		return
+	}
+
+	if !step.isCorrect(step.state, m.selectedPoint) {
+		m.tutorial.wrongMove = true
+		return
+	}
+
+	p := m.selectedPoint
+	m.tutorial.move = &p
+	m.tutorial.wrongMove = false
+	m.disksFlipped = getPointsToFlip(step.state.grid, p, step.state.player, step.state.rules)
+}
+
+func createTutorialView(m model, maxWidth int) string {
+	t := m.getTheme()
+	step := m.tutorial.getStep()
+
+	textStrings := []string{
+		t.accent1Text.Render(fmt.Sprintf("Tutorial %d of %d: %s", m.tutorial.index+1, len(tutorialSteps), step.title)),
+	}
+	for _, line := range step.text {
+		textStrings = append(textStrings, "", line)
+	}
+
+	if m.tutorial.isAwaitingMove() {
+		textStrings = append(textStrings, "")
+		if m.tutorial.wrongMove && step.hint != "" {
+			textStrings = append(textStrings, t.errorText.Render(fmt.Sprintf("Not quite: %s", step.hint)))
+		} else if slices.Contains(m.availablePoints, m.selectedPoint) {
+			textStrings = append(textStrings, t.successText.Render(fmt.Sprintf("Can place disk at %s", m.selectedPoint)))
+		} else {
+			textStrings = append(textStrings, t.errorText.Render(fmt.Sprintf("Cannot place disk at %s", m.selectedPoint)))
+		}
+	} else if m.tutorial.move != nil {
+		textStrings = append(textStrings, "", t.successText.Render(step.success))
+	}
+
+	textStrings = append(textStrings, "", t.secondaryText.Render(createTutorialHelp(m.keys, m.tutorial.isAwaitingMove())))
+
+	return lipgloss.NewStyle().
+		Width(maxWidth).
+		Render(lipgloss.JoinVertical(lipgloss.Left, textStrings...))
+}